@@ -0,0 +1,157 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - ADMIN API
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Trade Engine Server (REST)
+// Description: Admin endpoints of trade-engine-api-spec.yaml (FR-014),
+//              restricted to SUPER_ADMIN users
+// ============================================================================
+
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mytrader/trade-engine/internal/matching"
+)
+
+// userRolesHeader carries the authenticated user's roles, comma separated.
+// Set by the API gateway from the JWT claims, like userIDHeader.
+const userRolesHeader = "X-User-Roles"
+
+// roleSuperAdmin may call the admin endpoints
+const roleSuperAdmin = "SUPER_ADMIN"
+
+// requireRole lets only authenticated users with role through. Others get a
+// 401 or 403 problem.
+func requireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireUser(c); !ok {
+			return
+		}
+
+		for _, r := range strings.Split(c.GetHeader(userRolesHeader), ",") {
+			if strings.TrimSpace(r) == role {
+				c.Next()
+				return
+			}
+		}
+		writeProblem(c, http.StatusForbidden, "forbidden", "Forbidden", "user does not have "+role+" role")
+	}
+}
+
+// writeAdminError reports an engine error of an admin command. Errors
+// without a rejection code are refused state changes.
+func writeAdminError(c *gin.Context, err error) {
+	if _, ok := rejectionProblems[matching.ErrorCodeOf(err)]; ok {
+		writeEngineError(c, err)
+		return
+	}
+	writeProblem(c, http.StatusConflict, "conflict", "Conflict", err.Error())
+}
+
+type adminAPI struct {
+	engine *matching.MatchingEngine
+}
+
+// registerAdminRoutes adds the /admin endpoints behind SUPER_ADMIN
+// authentication
+func registerAdminRoutes(router *gin.Engine, engine *matching.MatchingEngine) {
+	api := &adminAPI{engine: engine}
+
+	admin := router.Group("/admin", requireRole(roleSuperAdmin))
+	admin.PUT("/symbols/:symbol/status", api.updateSymbolStatus)
+	admin.POST("/circuit-breaker/:symbol", api.overrideCircuitBreaker)
+}
+
+// updateSymbolStatus moves a symbol to another trading status. Delisting
+// cancels every resting and stop order of the symbol.
+func (api *adminAPI) updateSymbolStatus(c *gin.Context) {
+	var req struct {
+		Status          string     `json:"status" binding:"required"`
+		Reason          string     `json:"reason" binding:"required"`
+		EstimatedResume *time.Time `json:"estimated_resume"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeProblem(c, http.StatusBadRequest, "invalid-request", "Invalid Request", err.Error())
+		return
+	}
+
+	symbol := c.Param("symbol")
+	ob, ok := api.engine.GetOrderBook(symbol)
+	if !ok {
+		writeProblem(c, http.StatusNotFound, "not-found", "Not Found", "unknown symbol: "+symbol)
+		return
+	}
+
+	status := matching.SymbolStatus(req.Status)
+	if !status.IsValid() {
+		writeProblem(c, http.StatusBadRequest, "invalid-request", "Invalid Request", "invalid status: "+req.Status)
+		return
+	}
+
+	if err := api.engine.SetSymbolStatus(symbol, status, req.Reason); err != nil {
+		writeAdminError(c, err)
+		return
+	}
+
+	spec := ob.GetSpec()
+	c.JSON(http.StatusOK, gin.H{
+		"symbol":          symbol,
+		"status":          status,
+		"tick_size":       spec.TickSize.String(),
+		"min_order_size":  spec.MinQuantity.String(),
+		"max_order_size":  spec.MaxQuantity.String(),
+		"min_order_value": spec.MinNotional.String(),
+		"maker_fee":       api.engine.MakerFee.String(),
+		"taker_fee":       api.engine.TakerFee.String(),
+	})
+}
+
+// overrideCircuitBreaker halts a symbol (TRIGGER) or lifts a halt (RESET)
+func (api *adminAPI) overrideCircuitBreaker(c *gin.Context) {
+	var req struct {
+		Action          string `json:"action" binding:"required"`
+		DurationMinutes int    `json:"duration_minutes"`
+		Reason          string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeProblem(c, http.StatusBadRequest, "invalid-request", "Invalid Request", err.Error())
+		return
+	}
+
+	symbol := c.Param("symbol")
+	if _, ok := api.engine.GetOrderBook(symbol); !ok {
+		writeProblem(c, http.StatusNotFound, "not-found", "Not Found", "unknown symbol: "+symbol)
+		return
+	}
+
+	var err error
+	switch req.Action {
+	case "TRIGGER":
+		duration := time.Duration(req.DurationMinutes) * time.Minute
+		err = api.engine.TriggerCircuitBreaker(symbol, duration, req.Reason)
+	case "RESET":
+		err = api.engine.ResetCircuitBreaker(symbol, req.Reason)
+	default:
+		writeProblem(c, http.StatusBadRequest, "invalid-request", "Invalid Request", "action must be TRIGGER or RESET")
+		return
+	}
+
+	if err != nil {
+		writeAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"symbol":    symbol,
+		"action":    req.Action,
+		"halted":    api.engine.IsHalted(symbol),
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
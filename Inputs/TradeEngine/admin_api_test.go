@@ -0,0 +1,137 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - ADMIN API TESTS
+// ============================================================================
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mytrader/trade-engine/internal/matching"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAdminAPI returns the admin routes over an engine trading BTC/USDT
+func newTestAdminAPI(t *testing.T) (*gin.Engine, *matching.MatchingEngine) {
+	gin.SetMode(gin.TestMode)
+
+	engine := matching.NewMatchingEngine()
+	t.Cleanup(engine.Close)
+	engine.GetOrCreateOrderBook("BTC/USDT")
+
+	router := gin.New()
+	router.UseRawPath = true
+	registerAdminRoutes(router, engine)
+	return router, engine
+}
+
+// adminRequest sends an admin request as user with roles (none when empty)
+func adminRequest(t *testing.T, router *gin.Engine, method, path, user, roles, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		req.Header.Set(userIDHeader, user)
+	}
+	if roles != "" {
+		req.Header.Set(userRolesHeader, roles)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return w, resp
+}
+
+func TestAdminAPI_RequiresSuperAdmin(t *testing.T) {
+	router, engine := newTestAdminAPI(t)
+	trigger := `{"action":"TRIGGER","duration_minutes":5,"reason":"test"}`
+
+	w, problem := adminRequest(t, router, http.MethodPost, "/admin/circuit-breaker/BTC%2FUSDT", "", "", trigger)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, problemTypeBase+"unauthorized", problem["type"])
+
+	w, problem = adminRequest(t, router, http.MethodPost, "/admin/circuit-breaker/BTC%2FUSDT", "alice", "USER", trigger)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, problemTypeBase+"forbidden", problem["type"])
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.False(t, engine.IsHalted("BTC/USDT"))
+
+	w, resp := adminRequest(t, router, http.MethodPost, "/admin/circuit-breaker/BTC%2FUSDT", "root", "USER, SUPER_ADMIN", trigger)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, true, resp["halted"])
+	assert.True(t, engine.IsHalted("BTC/USDT"))
+}
+
+func TestAdminAPI_CircuitBreaker(t *testing.T) {
+	router, engine := newTestAdminAPI(t)
+	post := func(symbol, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		return adminRequest(t, router, http.MethodPost, "/admin/circuit-breaker/"+symbol, "root", roleSuperAdmin, body)
+	}
+
+	w, problem := post("BTC%2FUSDT", `{"action":"PAUSE"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, problemTypeBase+"invalid-request", problem["type"])
+
+	w, problem = post("DOGE%2FUSDT", `{"action":"TRIGGER"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, problemTypeBase+"not-found", problem["type"])
+
+	// Nothing to reset before a trigger
+	w, problem = post("BTC%2FUSDT", `{"action":"RESET","reason":"test"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, problemTypeBase+"conflict", problem["type"])
+
+	w, _ = post("BTC%2FUSDT", `{"action":"TRIGGER","duration_minutes":5}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w, resp := post("BTC%2FUSDT", `{"action":"RESET","reason":"resolved"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, false, resp["halted"])
+	assert.False(t, engine.IsHalted("BTC/USDT"))
+}
+
+func TestAdminAPI_SymbolStatus(t *testing.T) {
+	router, engine := newTestAdminAPI(t)
+	put := func(user, roles, symbol, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		return adminRequest(t, router, http.MethodPut, "/admin/symbols/"+symbol+"/status", user, roles, body)
+	}
+	_, err := engine.PlaceOrder(&matching.Order{
+		UserID: "alice", Symbol: "BTC/USDT", Side: matching.SideBuy, OrderType: matching.OrderTypeLimit,
+		TimeInForce: matching.TimeInForceGTC, Quantity: decimal.NewFromInt(1), Price: decimal.NewFromInt(50000),
+	})
+	require.NoError(t, err)
+
+	// Only a SUPER_ADMIN may delist, which would cancel alice's order
+	delist := `{"status":"DELISTED","reason":"delisting"}`
+	w, _ := put("", "", "BTC%2FUSDT", delist)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w, _ = put("alice", "USER", "BTC%2FUSDT", delist)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	status, _ := engine.GetSymbolStatus("BTC/USDT")
+	assert.Equal(t, matching.SymbolStatusActive, status)
+
+	w, problem := put("root", roleSuperAdmin, "BTC%2FUSDT", `{"status":"CLOSED","reason":"test"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, problemTypeBase+"invalid-request", problem["type"])
+
+	w, problem = put("root", roleSuperAdmin, "DOGE%2FUSDT", delist)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, problemTypeBase+"not-found", problem["type"])
+
+	w, problem = put("root", roleSuperAdmin, "BTC%2FUSDT", `{"status":"ACTIVE","reason":"already active"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, problemTypeBase+"conflict", problem["type"])
+
+	w, resp := put("root", roleSuperAdmin, "BTC%2FUSDT", `{"status":"HALTED","reason":"incident"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "HALTED", resp["status"])
+	assert.True(t, engine.IsHalted("BTC/USDT"))
+}
@@ -0,0 +1,258 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - ORDER BOOK COMMAND LOOP
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Concurrency Model)
+// Description: Each order book is owned by a single goroutine that executes
+//              place / cancel / amend / admin commands one at a time from a
+//              bounded queue. Callers get a Future for the result, so
+//              commands on a symbol are applied in arrival order without
+//              lock contention on the hot path.
+// ============================================================================
+
+package matching
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultCommandQueueSize bounds the pending commands per order book; once
+// full, submitters block until the book catches up (backpressure)
+const DefaultCommandQueueSize = 1024
+
+// ErrBookClosed is returned for commands submitted after the engine closed
+var ErrBookClosed = errors.New("order book is closed")
+
+// Future is the pending result of a command submitted to an order book
+type Future struct {
+	done   chan struct{}
+	trades []*Trade
+	err    error
+	order  *Order // Place commands: the order as the command left it
+}
+
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+func (f *Future) complete(trades []*Trade, err error) *Future {
+	f.trades, f.err = trades, err
+	close(f.done)
+	return f
+}
+
+// Done is closed once the command has executed
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the command has executed and returns its result
+func (f *Future) Wait() ([]*Trade, error) {
+	<-f.done
+	return f.trades, f.err
+}
+
+// Order waits for a place command and returns a copy of the order taken
+// when it finished, consistent with its trades: later commands may already
+// have filled or cancelled the order itself. nil for other commands and for
+// orders rejected before reaching a book.
+func (f *Future) Order() *Order {
+	<-f.done
+	return f.order
+}
+
+type bookCommand struct {
+	at     time.Time // Command time: journaled, and reused on replay
+	run    func() ([]*Trade, error)
+	future *Future
+}
+
+// ============================================================================
+// BOOK GOROUTINE
+// ============================================================================
+
+// start creates the command queue and launches the book's goroutine
+func (ob *OrderBook) start() {
+	size := ob.queueSize
+	if size <= 0 {
+		size = DefaultCommandQueueSize
+	}
+
+	ob.commands = make(chan bookCommand, size)
+	ob.stopped = make(chan struct{})
+	go ob.loop()
+}
+
+func (ob *OrderBook) loop() {
+	defer close(ob.stopped)
+
+	for cmd := range ob.commands {
+		ob.now = cmd.at
+		trades, err := cmd.run()
+		if ob.publishDelta != nil {
+			ob.publishDelta()
+		}
+		cmd.future.complete(trades, err)
+	}
+}
+
+// commandTime returns the time of the command being executed, or the wall
+// book's clock when the book is used directly (outside a command)
+func (ob *OrderBook) commandTime() time.Time {
+	if ob.now.IsZero() {
+		return ob.clock.Now()
+	}
+	return ob.now
+}
+
+// submit queues run for the book's goroutine. Commands must not submit to
+// their own book: that would deadlock.
+func (ob *OrderBook) submit(run func() ([]*Trade, error)) *Future {
+	return ob.submitAt(ob.clock.Now(), run)
+}
+
+// submitAt queues run as a command issued at the given time
+func (ob *OrderBook) submitAt(at time.Time, run func() ([]*Trade, error)) *Future {
+	return ob.submitFuture(at, newFuture(), run)
+}
+
+// submitFuture is submitAt completing a future created by the caller, so
+// run can attach results to it
+func (ob *OrderBook) submitFuture(at time.Time, future *Future, run func() ([]*Trade, error)) *Future {
+	ob.startOnce.Do(ob.start)
+
+	ob.sendMu.RLock()
+	defer ob.sendMu.RUnlock()
+
+	if ob.closed {
+		return future.complete(nil, ErrBookClosed)
+	}
+	ob.commands <- bookCommand{at: at, run: run, future: future}
+	return future
+}
+
+// execute runs a command on the book's goroutine and waits for it
+func (ob *OrderBook) execute(run func() ([]*Trade, error)) ([]*Trade, error) {
+	return ob.submit(run).Wait()
+}
+
+// do is execute for commands that don't trade
+func (ob *OrderBook) do(fn func() error) error {
+	return ob.doAt(ob.clock.Now(), fn)
+}
+
+func (ob *OrderBook) doAt(at time.Time, fn func() error) error {
+	_, err := ob.submitAt(at, func() ([]*Trade, error) {
+		return nil, fn()
+	}).Wait()
+	return err
+}
+
+// stop closes the queue; commands already queued still run
+func (ob *OrderBook) stop() {
+	ob.startOnce.Do(ob.start)
+
+	ob.sendMu.Lock()
+	if !ob.closed {
+		ob.closed = true
+		close(ob.commands)
+	}
+	ob.sendMu.Unlock()
+
+	<-ob.stopped
+}
+
+// ============================================================================
+// ENGINE API
+// ============================================================================
+
+// SubmitOrder queues an order for matching without waiting for the result
+func (me *MatchingEngine) SubmitOrder(order *Order) *Future {
+	return me.submitOrder(order, me.clock.Now())
+}
+
+func (me *MatchingEngine) submitOrder(order *Order, at time.Time) *Future {
+	// Assigned before journaling so replay sees the same ID
+	if order.OrderID == "" {
+		order.OrderID = me.orderIDs.NewID()
+	}
+	ob, ok := me.GetOrderBook(order.Symbol)
+	if !ok {
+		order.Status = OrderStatusRejected
+		return newFuture().complete(nil, errUnknownSymbol(order.Symbol))
+	}
+	future := newFuture()
+	return ob.submitFuture(at, future, func() ([]*Trade, error) {
+		defer func() { future.order = copyOrder(order) }()
+
+		entry := &JournalEntry{Type: JournalPlace, Symbol: order.Symbol, Order: order}
+		if err := me.record(ob, entry); err != nil {
+			order.Status = OrderStatusRejected
+			return nil, err
+		}
+
+		trades, err := me.placeOrder(order, ob)
+		if order.Status == OrderStatusRejected {
+			me.emitOrderRejected(ob, order, err)
+		}
+		return trades, err
+	})
+}
+
+// SubmitCancel queues a cancel without waiting for the result
+func (me *MatchingEngine) SubmitCancel(orderID string, symbol string, userID string) *Future {
+	return me.submitCancel(orderID, symbol, userID, me.clock.Now())
+}
+
+func (me *MatchingEngine) submitCancel(orderID string, symbol string, userID string, at time.Time) *Future {
+	ob, ok := me.GetOrderBook(symbol)
+	if !ok {
+		return newFuture().complete(nil, errUnknownSymbol(symbol))
+	}
+
+	return ob.submitAt(at, func() ([]*Trade, error) {
+		entry := &JournalEntry{Type: JournalCancel, Symbol: symbol, OrderID: orderID, UserID: userID}
+		if err := me.record(ob, entry); err != nil {
+			return nil, err
+		}
+		return nil, me.cancelOrder(orderID, userID, ob)
+	})
+}
+
+// ReadOrderBook runs fn on the book's goroutine, between commands, so it sees
+// a consistent book. fn must not retain the book or call back into the
+// engine. False if the symbol is unknown or the engine is closed.
+func (me *MatchingEngine) ReadOrderBook(symbol string, fn func(ob *OrderBook)) bool {
+	ob, ok := me.GetOrderBook(symbol)
+	if !ok {
+		return false
+	}
+
+	return ob.do(func() error {
+		fn(ob)
+		return nil
+	}) == nil
+}
+
+// Close stops every book's goroutine after its queued commands have run,
+// then ends every subscription once its events are delivered. Later
+// commands fail with ErrBookClosed.
+func (me *MatchingEngine) Close() {
+	for _, ob := range me.books() {
+		ob.stop()
+	}
+	me.bus.close()
+}
+
+// books returns the current order books
+func (me *MatchingEngine) books() []*OrderBook {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+
+	books := make([]*OrderBook, 0, len(me.OrderBooks))
+	for _, ob := range me.OrderBooks {
+		books = append(books, ob)
+	}
+	return books
+}
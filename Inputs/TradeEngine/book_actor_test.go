@@ -0,0 +1,109 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - ORDER BOOK COMMAND LOOP TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchingEngine_SubmitOrder_ArrivalOrder(t *testing.T) {
+	me := newTestEngine()
+	t.Cleanup(me.Close)
+
+	// Futures resolve in submission order: the first buy takes the whole ask
+	me.PlaceOrder(newTestOrder(SideSell, OrderTypeLimit, "1.0", "50000"))
+
+	first := me.SubmitOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000"))
+	second := me.SubmitOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000"))
+
+	trades, err := first.Wait()
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(trades))
+
+	<-second.Done()
+	trades, err = second.Wait()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(trades))
+}
+
+func TestMatchingEngine_SubmitOrder_OrderCopy(t *testing.T) {
+	me := newTestEngine()
+	t.Cleanup(me.Close)
+
+	me.PlaceOrder(newTestOrder(SideSell, OrderTypeLimit, "0.4", "50000"))
+	bid := newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000")
+	placed := me.SubmitOrder(bid)
+
+	// A later command fills the rest of the bid
+	me.PlaceOrder(newTestOrder(SideSell, OrderTypeLimit, "0.6", "50000"))
+	assert.Equal(t, OrderStatusFilled, bid.Status)
+
+	// The copy matches the place command's trades
+	trades, err := placed.Wait()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trades))
+	order := placed.Order()
+	require.NotNil(t, order)
+	assert.Equal(t, OrderStatusPartiallyFilled, order.Status)
+	assert.Equal(t, "0.4", order.FilledQuantity.String())
+
+	// Other commands carry no order
+	assert.Nil(t, me.SubmitCancel("order-1", "BTC/USDT", "").Order())
+}
+
+func TestMatchingEngine_SubmitOrder_InvalidOrder(t *testing.T) {
+	me := newTestEngine()
+	t.Cleanup(me.Close)
+
+	// Rejected by the book, before matching
+	order := newTestOrder(SideBuy, OrderTypeLimit, "0", "50000")
+	_, err := me.SubmitOrder(order).Wait()
+	assert.Error(t, err)
+	assert.Equal(t, OrderStatusRejected, order.Status)
+
+	_, err = me.SubmitCancel("order-1", "DOGE/USDT", "").Wait()
+	assert.Error(t, err)
+}
+
+func TestMatchingEngine_Close(t *testing.T) {
+	me := newTestEngine()
+	me.CommandQueueSize = 4
+
+	// Queued commands still run before the goroutine exits
+	futures := make([]*Future, 0, 10)
+	for i := 0; i < 10; i++ {
+		futures = append(futures, me.SubmitOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000")))
+	}
+	me.Close()
+
+	for _, future := range futures {
+		_, err := future.Wait()
+		assert.NoError(t, err)
+	}
+
+	_, err := me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000"))
+	assert.ErrorIs(t, err, ErrBookClosed)
+	assert.False(t, me.ReadOrderBook("BTC/USDT", func(*OrderBook) {}))
+
+	// Closing twice is harmless
+	me.Close()
+}
+
+func TestMatchingEngine_ReadOrderBook(t *testing.T) {
+	me := newTestEngine()
+	t.Cleanup(me.Close)
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000"))
+
+	var bestBid string
+	assert.True(t, me.ReadOrderBook("BTC/USDT", func(ob *OrderBook) {
+		bestBid = ob.GetBestBid().String()
+	}))
+	assert.Equal(t, "50000", bestBid)
+
+	assert.False(t, me.ReadOrderBook("DOGE/USDT", func(*OrderBook) {}))
+}
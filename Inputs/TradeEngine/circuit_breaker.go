@@ -0,0 +1,313 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - VOLATILITY CIRCUIT BREAKER
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Market Protection)
+// Description: Halts a symbol when its trade price moves more than a
+//              threshold within a rolling window (RMR-003), with manual
+//              trigger/reset for admins (FR-014)
+// ============================================================================
+
+package matching
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ============================================================================
+// TYPES
+// ============================================================================
+
+// CircuitBreakerConfig configures the volatility halt of a symbol.
+// A zero ThresholdPercent disables automatic halts.
+type CircuitBreakerConfig struct {
+	ThresholdPercent decimal.Decimal // Max price move within Window (%)
+	Window           time.Duration   // Rolling window for price moves
+	Cooldown         time.Duration   // Halt duration once triggered
+}
+
+// NewCircuitBreakerConfig parses a CircuitBreakerConfig (as found in
+// config.yaml). An empty threshold leaves automatic halts disabled.
+func NewCircuitBreakerConfig(thresholdPercent string, window, cooldown time.Duration) (CircuitBreakerConfig, error) {
+	cfg := CircuitBreakerConfig{
+		Window:   window,
+		Cooldown: cooldown,
+	}
+
+	if thresholdPercent != "" {
+		d, err := decimal.NewFromString(thresholdPercent)
+		if err != nil {
+			return CircuitBreakerConfig{}, fmt.Errorf("invalid threshold_percentage %q: %w", thresholdPercent, err)
+		}
+		if d.IsNegative() {
+			return CircuitBreakerConfig{}, errors.New("threshold_percentage must not be negative")
+		}
+		cfg.ThresholdPercent = d
+	}
+
+	if cfg.ThresholdPercent.IsPositive() && (window <= 0 || cooldown <= 0) {
+		return CircuitBreakerConfig{}, errors.New("circuit breaker window and cooldown must be positive")
+	}
+
+	return cfg, nil
+}
+
+type CircuitBreakerAction string
+
+const (
+	CircuitBreakerTriggered CircuitBreakerAction = "TRIGGERED"
+	CircuitBreakerReset     CircuitBreakerAction = "RESET"
+)
+
+// CircuitBreakerEvent reports a symbol entering or leaving a halt
+type CircuitBreakerEvent struct {
+	EventHeader
+	Action         CircuitBreakerAction `json:"action"`
+	Reason         string               `json:"reason"`
+	ReferencePrice decimal.Decimal      `json:"reference_price"` // Window price the move is measured from
+	TriggerPrice   decimal.Decimal      `json:"trigger_price"`   // Trade price that tripped the breaker
+	HaltedUntil    time.Time            `json:"halted_until"`
+}
+
+// Circuit breaker reasons
+const (
+	CircuitBreakerReasonVolatility = "VOLATILITY"
+	CircuitBreakerReasonCooldown   = "COOLDOWN_EXPIRED"
+)
+
+type pricePoint struct {
+	price decimal.Decimal
+	at    time.Time
+}
+
+// CircuitBreaker tracks recent trade prices of one OrderBook.
+// It is guarded by the book's command lock.
+type CircuitBreaker struct {
+	Config CircuitBreakerConfig
+
+	window      []pricePoint
+	halted      bool
+	haltedUntil time.Time
+}
+
+// ============================================================================
+// BREAKER STATE
+// ============================================================================
+
+// record adds a trade price to the rolling window and reports whether it
+// moved more than the threshold away from any price still in the window
+func (cb *CircuitBreaker) record(price decimal.Decimal, at time.Time) (ref decimal.Decimal, tripped bool) {
+	if !cb.Config.ThresholdPercent.IsPositive() || cb.halted {
+		return decimal.Zero, false
+	}
+
+	// Drop prices that fell out of the window
+	cutoff := at.Add(-cb.Config.Window)
+	keep := 0
+	for keep < len(cb.window) && cb.window[keep].at.Before(cutoff) {
+		keep++
+	}
+	cb.window = cb.window[keep:]
+
+	for _, p := range cb.window {
+		move := price.Sub(p.price).Abs().Mul(hundred).Div(p.price)
+		if move.GreaterThan(cb.Config.ThresholdPercent) {
+			cb.window = nil
+			return p.price, true
+		}
+	}
+
+	cb.window = append(cb.window, pricePoint{price: price, at: at})
+	return decimal.Zero, false
+}
+
+// halt puts the breaker in the halted state until the given time
+func (cb *CircuitBreaker) halt(until time.Time) {
+	cb.halted = true
+	cb.haltedUntil = until
+	cb.window = nil
+}
+
+// expired reports whether a halt's cooldown is over at now
+func (cb *CircuitBreaker) expired(now time.Time) bool {
+	return cb.halted && !now.Before(cb.haltedUntil)
+}
+
+// reset clears the halted state
+func (cb *CircuitBreaker) reset() {
+	cb.halted = false
+	cb.haltedUntil = time.Time{}
+}
+
+// ============================================================================
+// ENGINE INTEGRATION
+// ============================================================================
+
+// SetCircuitBreaker configures the volatility circuit breaker of a symbol
+func (me *MatchingEngine) SetCircuitBreaker(symbol string, cfg CircuitBreakerConfig) error {
+	ob, ok := me.GetOrderBook(symbol)
+	if !ok {
+		return errUnknownSymbol(symbol)
+	}
+
+	return ob.do(func() error {
+		ob.Breaker.Config = cfg
+		return nil
+	})
+}
+
+// IsHalted reports whether the symbol is HALTED
+func (me *MatchingEngine) IsHalted(symbol string) bool {
+	status, _ := me.GetSymbolStatus(symbol)
+	return status == SymbolStatusHalted
+}
+
+// TriggerCircuitBreaker halts a symbol manually. A zero duration uses the
+// configured cooldown.
+func (me *MatchingEngine) TriggerCircuitBreaker(symbol string, duration time.Duration, reason string) error {
+	return me.triggerCircuitBreakerAt(symbol, duration, reason, me.clock.Now())
+}
+
+func (me *MatchingEngine) triggerCircuitBreakerAt(symbol string, duration time.Duration, reason string, at time.Time) error {
+	ob, ok := me.GetOrderBook(symbol)
+	if !ok {
+		return errUnknownSymbol(symbol)
+	}
+
+	return ob.doAt(at, func() error {
+		entry := &JournalEntry{Type: JournalHalt, Symbol: symbol, Duration: duration, Reason: reason}
+		if err := me.record(ob, entry); err != nil {
+			return err
+		}
+
+		if duration <= 0 {
+			duration = ob.Breaker.Config.Cooldown
+		}
+		if duration <= 0 {
+			return errors.New("circuit breaker duration must be positive")
+		}
+		if ob.Status != SymbolStatusActive && ob.Status != SymbolStatusHalted {
+			return fmt.Errorf("cannot halt %s while %s", symbol, ob.Status)
+		}
+
+		me.haltOrderBook(ob, reason, decimal.Zero, decimal.Zero, ob.now.Add(duration))
+		return nil
+	})
+}
+
+// ResetCircuitBreaker lifts a halt before its cooldown expires
+func (me *MatchingEngine) ResetCircuitBreaker(symbol string, reason string) error {
+	return me.resetCircuitBreakerAt(symbol, reason, me.clock.Now())
+}
+
+func (me *MatchingEngine) resetCircuitBreakerAt(symbol string, reason string, at time.Time) error {
+	ob, ok := me.GetOrderBook(symbol)
+	if !ok {
+		return errUnknownSymbol(symbol)
+	}
+
+	return ob.doAt(at, func() error {
+		entry := &JournalEntry{Type: JournalResume, Symbol: symbol, Reason: reason}
+		if err := me.record(ob, entry); err != nil {
+			return err
+		}
+
+		if !ob.Breaker.halted {
+			return errors.New("circuit breaker is not triggered")
+		}
+
+		me.resumeOrderBook(ob, reason)
+		return nil
+	})
+}
+
+// CheckCircuitBreakers resumes symbols whose cooldown has expired.
+// Called periodically so the reset event is not delayed until the next order.
+func (me *MatchingEngine) CheckCircuitBreakers() {
+	for _, ob := range me.books() {
+		ob.do(func() error {
+			if !ob.Breaker.expired(ob.now) {
+				return nil
+			}
+
+			// Journaled, so a replay resumes at the same point
+			entry := &JournalEntry{Type: JournalResume, Symbol: ob.Symbol, Reason: CircuitBreakerReasonCooldown}
+			if err := me.record(ob, entry); err != nil {
+				return err
+			}
+			me.resumeOrderBook(ob, CircuitBreakerReasonCooldown)
+			return nil
+		})
+	}
+}
+
+// recordTrade feeds an executed trade price to the circuit breaker and
+// halts the symbol when it trips it. Called for every trade as it happens,
+// so a single order sweeping the book is stopped at the breach.
+// Runs on the book's goroutine.
+func (me *MatchingEngine) recordTrade(ob *OrderBook, trade *Trade) (halted bool) {
+	if ob.Status != SymbolStatusActive {
+		return false
+	}
+
+	ref, tripped := ob.Breaker.record(trade.Price, trade.ExecutedAt)
+	if tripped {
+		until := trade.ExecutedAt.Add(ob.Breaker.Config.Cooldown)
+		me.haltOrderBook(ob, CircuitBreakerReasonVolatility, ref, trade.Price, until)
+	}
+	return tripped
+}
+
+// cancelAtHalt cancels what is left of a taker whose trade halted the
+// symbol. Its fills stand; the rest neither matches further nor rests.
+func cancelAtHalt(ob *OrderBook, order *Order, remaining matchQuantity) matchQuantity {
+	if !remaining.IsPositive() {
+		return remaining
+	}
+
+	cancelWithReason(order, StatusReasonCircuitBreaker, ob.now)
+	return matchQuantity{fixed: ob.fx != nil}
+}
+
+func (me *MatchingEngine) resumeIfExpired(ob *OrderBook, now time.Time) {
+	if ob.Breaker.expired(now) {
+		me.resumeOrderBook(ob, CircuitBreakerReasonCooldown)
+	}
+}
+
+func (me *MatchingEngine) haltOrderBook(ob *OrderBook, reason string, ref, trigger decimal.Decimal, until time.Time) {
+	ob.Breaker.halt(until)
+	if ob.Status != SymbolStatusHalted {
+		me.setSymbolStatus(ob, SymbolStatusHalted, reason)
+	}
+
+	me.emitCircuitBreaker(ob, CircuitBreakerEvent{
+		Action:         CircuitBreakerTriggered,
+		Reason:         reason,
+		ReferencePrice: ref,
+		TriggerPrice:   trigger,
+		HaltedUntil:    until,
+	})
+}
+
+func (me *MatchingEngine) resumeOrderBook(ob *OrderBook, reason string) {
+	ob.Breaker.reset()
+	me.setSymbolStatus(ob, SymbolStatusActive, reason)
+
+	me.emitCircuitBreaker(ob, CircuitBreakerEvent{
+		Action: CircuitBreakerReset,
+		Reason: reason,
+	})
+}
+
+// emitCircuitBreaker publishes a circuit breaker event
+func (me *MatchingEngine) emitCircuitBreaker(ob *OrderBook, event CircuitBreakerEvent) {
+	me.publish(ob, EventCircuitBreaker, func(h EventHeader) Event {
+		event.EventHeader = h
+		return &event
+	})
+}
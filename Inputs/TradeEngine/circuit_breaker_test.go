@@ -0,0 +1,204 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - CIRCUIT BREAKER TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBreakerEngine returns an engine halting BTC/USDT on a >10% move
+// within one minute, recording circuit breaker events
+func newTestBreakerEngine(t *testing.T, opts ...EngineOption) (*MatchingEngine, *eventRecorder) {
+	cfg, err := NewCircuitBreakerConfig("10", time.Minute, 5*time.Minute)
+	require.NoError(t, err)
+
+	me := newTestEngine(opts...)
+	me.SetCircuitBreaker("BTC/USDT", cfg)
+
+	return me, recordEvents(t, me)
+}
+
+func TestNewCircuitBreakerConfig(t *testing.T) {
+	_, err := NewCircuitBreakerConfig("abc", time.Minute, time.Minute)
+	assert.Error(t, err)
+
+	_, err = NewCircuitBreakerConfig("10", 0, time.Minute)
+	assert.Error(t, err)
+
+	// Disabled breaker needs no window
+	cfg, err := NewCircuitBreakerConfig("", 0, 0)
+	require.NoError(t, err)
+	assert.True(t, cfg.ThresholdPercent.IsZero())
+}
+
+func TestCircuitBreaker_RollingWindow(t *testing.T) {
+	cb := CircuitBreaker{Config: CircuitBreakerConfig{
+		ThresholdPercent: decimal.NewFromInt(10),
+		Window:           time.Minute,
+		Cooldown:         5 * time.Minute,
+	}}
+	start := testEpoch
+
+	_, tripped := cb.record(decimal.NewFromInt(50000), start)
+	assert.False(t, tripped)
+
+	// Exactly 10% is allowed
+	_, tripped = cb.record(decimal.NewFromInt(55000), start.Add(10*time.Second))
+	assert.False(t, tripped)
+
+	// 50000 has left the window: 56000 is only ~1.8% above 55000
+	_, tripped = cb.record(decimal.NewFromInt(56000), start.Add(65*time.Second))
+	assert.False(t, tripped)
+
+	ref, tripped := cb.record(decimal.NewFromInt(49000), start.Add(70*time.Second))
+	assert.True(t, tripped)
+	assert.Equal(t, "55000", ref.String())
+}
+
+func TestMatchingEngine_CircuitBreaker_HaltsOnVolatility(t *testing.T) {
+	me, events := newTestBreakerEngine(t)
+	tradeAt(t, me, "50000")
+
+	resting := newTestOrder(SideBuy, OrderTypeLimit, "1.0", "44000")
+	_, err := me.PlaceOrder(resting)
+	require.NoError(t, err)
+
+	// A 12% drop trips the breaker; the trade itself still executes
+	trades, err := me.PlaceOrder(newTestMarketOrder(SideSell, "0.5"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(trades))
+
+	assert.True(t, me.IsHalted("BTC/USDT"))
+	require.Equal(t, 1, len(events.breakers()))
+	event := events.breakers()[0]
+	assert.Equal(t, CircuitBreakerTriggered, event.Action)
+	assert.Equal(t, CircuitBreakerReasonVolatility, event.Reason)
+	assert.Equal(t, "50000", event.ReferencePrice.String())
+	assert.Equal(t, "44000", event.TriggerPrice.String())
+
+	// New orders and amends are rejected
+	order := newTestOrder(SideBuy, OrderTypeLimit, "1.0", "44000")
+	_, err = me.PlaceOrder(order)
+	assert.Equal(t, ErrCodeSymbolHalted, ErrorCodeOf(err))
+	assert.Equal(t, OrderStatusRejected, order.Status)
+
+	_, err = me.AmendOrder(resting.OrderID, resting.Symbol, resting.UserID, decimal.Zero, decimal.NewFromFloat(0.8))
+	assert.Equal(t, ErrCodeSymbolHalted, ErrorCodeOf(err))
+
+	// Cancels are still allowed
+	assert.NoError(t, me.CancelOrder(resting.OrderID, resting.Symbol, resting.UserID))
+	assert.Equal(t, OrderStatusCancelled, resting.Status)
+}
+
+func TestMatchingEngine_CircuitBreaker_StopsMatchingAtBreach(t *testing.T) {
+	me, events := newTestBreakerEngine(t)
+	tradeAt(t, me, "50000")
+
+	me.PlaceOrder(newTestOrder(SideSell, OrderTypeLimit, "0.5", "51000"))
+	me.PlaceOrder(newTestOrder(SideSell, OrderTypeLimit, "0.5", "56000"))
+	far := newTestOrder(SideSell, OrderTypeLimit, "0.5", "57000")
+	me.PlaceOrder(far)
+
+	// The 56000 trade is 12% above 50000: it executes, the 57000 ask doesn't
+	buy := newTestOrder(SideBuy, OrderTypeLimit, "2.0", "57000")
+	trades, err := me.PlaceOrder(buy)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(trades))
+	assert.Equal(t, "56000", trades[1].Price.String())
+
+	assert.True(t, me.IsHalted("BTC/USDT"))
+	require.Equal(t, 1, len(events.breakers()))
+	assert.Equal(t, "56000", events.breakers()[0].TriggerPrice.String())
+
+	// The rest of the buy is cancelled rather than left crossing the book
+	assert.Equal(t, OrderStatusCancelled, buy.Status)
+	assert.Equal(t, StatusReasonCircuitBreaker, buy.StatusReason)
+	assert.Equal(t, "1", buy.FilledQuantity.String())
+	assert.Equal(t, OrderStatusOpen, far.Status)
+
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	assert.True(t, ob.GetBestBid().IsZero())
+	assert.Equal(t, "57000", ob.GetBestAsk().String())
+}
+
+func TestMatchingEngine_CircuitBreaker_CooldownExpiry(t *testing.T) {
+	clock := NewFakeClock(testEpoch)
+	me, events := newTestBreakerEngine(t, WithClock(clock))
+	require.NoError(t, me.TriggerCircuitBreaker("BTC/USDT", time.Minute, "manual"))
+	assert.Equal(t, testEpoch.Add(time.Minute), events.breakers()[0].HaltedUntil)
+
+	// Cooldown not over yet
+	clock.Advance(59 * time.Second)
+	me.CheckCircuitBreakers()
+	assert.True(t, me.IsHalted("BTC/USDT"))
+
+	// The first order after the cooldown resumes trading
+	resumedAt := clock.Advance(time.Minute)
+	_, err := me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000"))
+
+	assert.NoError(t, err)
+	assert.False(t, me.IsHalted("BTC/USDT"))
+	require.Equal(t, 2, len(events.breakers()))
+	assert.Equal(t, CircuitBreakerReset, events.breakers()[1].Action)
+	assert.Equal(t, CircuitBreakerReasonCooldown, events.breakers()[1].Reason)
+	assert.Equal(t, resumedAt, events.breakers()[1].Timestamp)
+}
+
+func TestMatchingEngine_CircuitBreaker_CheckResumesAtCooldownEnd(t *testing.T) {
+	clock := NewFakeClock(testEpoch)
+	me, events := newTestBreakerEngine(t, WithClock(clock))
+	require.NoError(t, me.TriggerCircuitBreaker("BTC/USDT", time.Minute, "manual"))
+
+	// The halt ends exactly at HaltedUntil
+	clock.Advance(time.Minute)
+	me.CheckCircuitBreakers()
+	assert.False(t, me.IsHalted("BTC/USDT"))
+	require.Equal(t, 2, len(events.breakers()))
+	assert.Equal(t, testEpoch.Add(time.Minute), events.breakers()[1].Timestamp)
+}
+
+func TestMatchingEngine_CircuitBreaker_ManualTriggerAndReset(t *testing.T) {
+	me, events := newTestBreakerEngine(t, WithClock(NewFakeClock(testEpoch)))
+
+	assert.Error(t, me.TriggerCircuitBreaker("DOGE/USDT", time.Minute, "manual"))
+	assert.Error(t, me.ResetCircuitBreaker("BTC/USDT", "not halted"))
+
+	// Zero duration falls back to the configured cooldown
+	require.NoError(t, me.TriggerCircuitBreaker("BTC/USDT", 0, "news"))
+	assert.True(t, me.IsHalted("BTC/USDT"))
+	assert.Equal(t, "news", events.breakers()[0].Reason)
+	assert.Equal(t, testEpoch.Add(5*time.Minute), events.breakers()[0].HaltedUntil)
+	assert.Equal(t, testEpoch, events.breakers()[0].Timestamp)
+
+	_, err := me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000"))
+	assert.Equal(t, ErrCodeSymbolHalted, ErrorCodeOf(err))
+
+	require.NoError(t, me.ResetCircuitBreaker("BTC/USDT", "resolved"))
+	assert.False(t, me.IsHalted("BTC/USDT"))
+	assert.Equal(t, CircuitBreakerReset, events.breakers()[1].Action)
+
+	_, err = me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000"))
+	assert.NoError(t, err)
+}
+
+func TestMatchingEngine_CircuitBreaker_HaltedStopsDoNotFire(t *testing.T) {
+	me, _ := newTestBreakerEngine(t)
+	tradeAt(t, me, "50000")
+
+	stop := newTestStopOrder(SideSell, "1.0", "46000")
+	_, err := me.PlaceOrder(stop)
+	require.NoError(t, err)
+
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "44000"))
+	me.PlaceOrder(newTestMarketOrder(SideSell, "0.5"))
+
+	require.True(t, me.IsHalted("BTC/USDT"))
+	assert.Equal(t, OrderStatusOpen, stop.Status)
+}
@@ -0,0 +1,46 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - CLOCK AND ID GENERATION TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewStepClock(start, time.Second)
+
+	assert.Equal(t, start, clock.Now())
+	assert.Equal(t, start.Add(time.Second), clock.Now())
+	assert.Equal(t, start.Add(2*time.Second), clock.Now())
+}
+
+func TestSeededIDGenerator(t *testing.T) {
+	a, b := NewSeededIDGenerator("seed"), NewSeededIDGenerator("seed")
+
+	first := a.NewID()
+	assert.Equal(t, first, b.NewID())
+	assert.NotEqual(t, first, a.NewID())
+	assert.NotEqual(t, first, NewSeededIDGenerator("other").NewID())
+
+	_, err := uuid.Parse(first)
+	require.NoError(t, err)
+}
+
+func TestFillTradeID(t *testing.T) {
+	first := fillTradeID("order-1", decimal.Zero)
+	assert.Equal(t, first, fillTradeID("order-1", decimal.RequireFromString("0.000")))
+	assert.NotEqual(t, first, fillTradeID("order-1", decimal.RequireFromString("0.5")))
+	assert.NotEqual(t, first, fillTradeID("order-2", decimal.Zero))
+
+	_, err := uuid.Parse(first)
+	require.NoError(t, err)
+}
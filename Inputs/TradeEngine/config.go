@@ -7,17 +7,21 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	Database DatabaseConfig `yaml:"database"`
-	Redis    RedisConfig    `yaml:"redis"`
-	Kafka    KafkaConfig    `yaml:"kafka"`
-	Logging  LoggingConfig  `yaml:"logging"`
+	Server      ServerConfig      `yaml:"server"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Redis       RedisConfig       `yaml:"redis"`
+	Kafka       KafkaConfig       `yaml:"kafka"`
+	Logging     LoggingConfig     `yaml:"logging"`
+	Trading     TradingConfig     `yaml:"trading"`
+	Persistence PersistenceConfig `yaml:"persistence"`
+	Features    FeaturesConfig    `yaml:"features"`
 }
 
 type ServerConfig struct {
@@ -26,6 +30,10 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `yaml:"read_timeout"`
 	WriteTimeout time.Duration `yaml:"write_timeout"`
 	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+
+	// Browser origins allowed to open /ws. Clients sending no Origin
+	// header are always allowed.
+	AllowedOrigins []string `yaml:"allowed_origins"`
 }
 
 type DatabaseConfig struct {
@@ -52,6 +60,10 @@ type KafkaConfig struct {
 		TradeEvents string `yaml:"trade_events"`
 		OrderEvents string `yaml:"order_events"`
 	} `yaml:"topics"`
+
+	// Engine events queued for the publisher, and what a full queue does
+	BufferSize   int    `yaml:"buffer_size"`
+	Backpressure string `yaml:"backpressure"` // block, drop_oldest, drop_newest, disconnect
 }
 
 type LoggingConfig struct {
@@ -59,6 +71,70 @@ type LoggingConfig struct {
 	Format string `yaml:"format"` // json, text
 }
 
+// PersistenceConfig holds engine durability settings (NFR-005)
+type PersistenceConfig struct {
+	Journal   JournalConfig  `yaml:"journal"`
+	Snapshots SnapshotConfig `yaml:"snapshots"`
+}
+
+// JournalConfig holds the write-ahead command journal settings
+type JournalConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	Dir           string        `yaml:"dir"`            // Segment files
+	Fsync         string        `yaml:"fsync"`          // always, interval, never
+	FsyncInterval time.Duration `yaml:"fsync_interval"` // For fsync: interval
+}
+
+// SnapshotConfig holds the order book snapshot settings
+type SnapshotConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Dir      string        `yaml:"dir"`
+	Interval time.Duration `yaml:"interval"` // Also written on graceful shutdown
+	Retain   int           `yaml:"retain"`   // Snapshots kept; older journal segments are deleted
+}
+
+// FeaturesConfig switches optional features on (MVP feature flags)
+type FeaturesConfig struct {
+	PaperTrading      bool `yaml:"paper_trading"`
+	BrokerIntegration bool `yaml:"broker_integration"`
+	WebSocket         bool `yaml:"websocket"` // /ws market data streams
+	AdminPanel        bool `yaml:"admin_panel"`
+}
+
+type TradingConfig struct {
+	Matching       MatchingConfig       `yaml:"matching"`
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
+}
+
+// MatchingConfig holds default symbol trading rules (RMR-002).
+// Decimal values are kept as strings to avoid float rounding.
+type MatchingConfig struct {
+	MaxOrderBookDepth int    `yaml:"max_order_book_depth"`
+	CommandQueueSize  int    `yaml:"command_queue_size"` // Pending commands per symbol
+	TickSize          string `yaml:"tick_size"`
+	StepSize          string `yaml:"step_size"`
+	MinOrderSize      string `yaml:"min_order_size"`
+	MaxOrderSize      string `yaml:"max_order_size"`
+	MinOrderValue     string `yaml:"min_order_value"` // Quote currency
+
+	// Price band (RMR-004): ±% from last trade / mid price
+	PriceBandPercentage string `yaml:"price_band_percentage"`
+
+	// Match on int64 ticks/lots scaled by tick_size/step_size (NFR-002)
+	FixedPoint bool `yaml:"fixed_point"`
+
+	// Public trades kept per symbol for GET /market-data/trades
+	RecentTrades int `yaml:"recent_trades"`
+}
+
+// CircuitBreakerConfig holds the volatility halt settings (RMR-003)
+type CircuitBreakerConfig struct {
+	Enabled             bool          `yaml:"enabled"`
+	ThresholdPercentage string        `yaml:"threshold_percentage"` // Max price move within Window
+	Window              time.Duration `yaml:"window"`
+	Cooldown            time.Duration `yaml:"cooldown"` // Halt duration
+}
+
 // Load reads configuration from file or environment variables
 func Load() (*Config, error) {
 	configPath := getEnv("CONFIG_PATH", "config.yaml")
@@ -87,12 +163,39 @@ func Load() (*Config, error) {
 			PoolSize: 100,
 		},
 		Kafka: KafkaConfig{
-			Brokers: []string{"localhost:9092"},
+			Brokers:      []string{"localhost:9092"},
+			BufferSize:   1024,
+			Backpressure: "block",
 		},
 		Logging: LoggingConfig{
 			Level:  "info",
 			Format: "json",
 		},
+		Trading: TradingConfig{
+			Matching: MatchingConfig{
+				MaxOrderBookDepth: 1000,
+				CommandQueueSize:  1024,
+				TickSize:          "0.01",
+				RecentTrades:      1000,
+			},
+			CircuitBreaker: CircuitBreakerConfig{
+				ThresholdPercentage: "10",
+				Window:              time.Minute,
+				Cooldown:            5 * time.Minute,
+			},
+		},
+		Persistence: PersistenceConfig{
+			Journal: JournalConfig{
+				Dir:           "data/journal",
+				Fsync:         "always",
+				FsyncInterval: 10 * time.Millisecond,
+			},
+			Snapshots: SnapshotConfig{
+				Dir:      "data/snapshots",
+				Interval: time.Minute,
+				Retain:   2,
+			},
+		},
 	}
 
 	// Load from file if exists
@@ -122,6 +225,14 @@ func Load() (*Config, error) {
 }
 
 func (c *Config) overrideFromEnv() {
+	// Persistence
+	if dir := getEnv("JOURNAL_DIR", ""); dir != "" {
+		c.Persistence.Journal.Dir = dir
+	}
+	if dir := getEnv("SNAPSHOT_DIR", ""); dir != "" {
+		c.Persistence.Snapshots.Dir = dir
+	}
+
 	// Server
 	if port := getEnv("PORT", ""); port != "" {
 		fmt.Sscanf(port, "%d", &c.Server.Port)
@@ -129,6 +240,9 @@ func (c *Config) overrideFromEnv() {
 	if mode := getEnv("GIN_MODE", ""); mode != "" {
 		c.Server.Mode = mode
 	}
+	if origins := getEnv("ALLOWED_ORIGINS", ""); origins != "" {
+		c.Server.AllowedOrigins = strings.Split(origins, ",")
+	}
 
 	// Database
 	if host := getEnv("DB_HOST", ""); host != "" {
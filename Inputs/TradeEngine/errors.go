@@ -0,0 +1,72 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - ORDER ERRORS
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Structured Rejections)
+// Description: Rejections carrying a machine-readable code so callers (REST,
+//              WebSocket, Kafka) can map them without parsing messages
+// ============================================================================
+
+package matching
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode identifies why an order was rejected
+type ErrorCode string
+
+const (
+	// Symbol trading rules (RMR-002)
+	ErrCodeInvalidTickSize  ErrorCode = "INVALID_TICK_SIZE"
+	ErrCodeInvalidStepSize  ErrorCode = "INVALID_STEP_SIZE"
+	ErrCodeQuantityTooSmall ErrorCode = "QUANTITY_TOO_SMALL"
+	ErrCodeQuantityTooLarge ErrorCode = "QUANTITY_TOO_LARGE"
+	ErrCodeNotionalTooSmall ErrorCode = "NOTIONAL_TOO_SMALL"
+
+	// Market protection (RMR-004)
+	ErrCodePriceOutOfBand ErrorCode = "PRICE_OUT_OF_BAND"
+
+	// Symbol status
+	ErrCodeUnknownSymbol    ErrorCode = "UNKNOWN_SYMBOL" // No order book was set up for the symbol
+	ErrCodeSymbolHalted     ErrorCode = "SYMBOL_HALTED"
+	ErrCodeSymbolNotTrading ErrorCode = "SYMBOL_NOT_TRADING"
+
+	// Time in force
+	ErrCodeFillOrKill ErrorCode = "FOK_NOT_FILLED"
+
+	// Cancels
+	ErrCodeOrderNotFound  ErrorCode = "ORDER_NOT_FOUND"  // Unknown, no longer resting, or another user's
+	ErrCodeOrderNotActive ErrorCode = "ORDER_NOT_ACTIVE" // Resting but not OPEN or PARTIALLY_FILLED
+)
+
+// OrderError is a rejection with a structured error code
+type OrderError struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (e *OrderError) Error() string {
+	return e.Message
+}
+
+func newOrderError(code ErrorCode, format string, args ...interface{}) *OrderError {
+	return &OrderError{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func errUnknownSymbol(symbol string) *OrderError {
+	return newOrderError(ErrCodeUnknownSymbol, "unknown symbol: %s", symbol)
+}
+
+// ErrorCodeOf returns the structured code of err, or "" for plain errors
+func ErrorCodeOf(err error) ErrorCode {
+	var orderErr *OrderError
+	if errors.As(err, &orderErr) {
+		return orderErr.Code
+	}
+	return ""
+}
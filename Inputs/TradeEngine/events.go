@@ -0,0 +1,631 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - EVENT BUS
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Event Distribution)
+// Description: Typed engine events fanned out to any number of subscribers.
+//              Each subscriber has a bounded queue drained by its own
+//              goroutine, so a slow consumer (Kafka, WebSocket) never runs
+//              inside the matching loop; what happens when its queue fills
+//              up is chosen per subscriber (backpressure policy).
+// ============================================================================
+
+package matching
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ============================================================================
+// EVENTS
+// ============================================================================
+
+type EventType string
+
+const (
+	EventOrderAccepted       EventType = "ORDER_ACCEPTED"
+	EventOrderRejected       EventType = "ORDER_REJECTED"
+	EventTrade               EventType = "TRADE"
+	EventOrderFilled         EventType = "ORDER_FILLED"
+	EventOrderCancelled      EventType = "ORDER_CANCELLED"
+	EventBookDelta           EventType = "BOOK_DELTA"
+	EventSymbolStatusChanged EventType = "SYMBOL_STATUS_CHANGED"
+	EventCircuitBreaker      EventType = "CIRCUIT_BREAKER"
+)
+
+// EventHeader is common to every event
+type EventHeader struct {
+	Type      EventType `json:"type"`
+	Symbol    string    `json:"symbol"`
+	Timestamp time.Time `json:"timestamp"`
+
+	EventSequence
+}
+
+// Header returns the event's type, symbol, time and sequence numbers
+func (h EventHeader) Header() EventHeader {
+	return h
+}
+
+// Event is one of the *Event types below. Events are shared by all
+// subscribers and must not be modified.
+type Event interface {
+	Header() EventHeader
+}
+
+// OrderAcceptedEvent reports an order entering the book or the stop
+// watchlist, a triggered stop order released to match (status TRIGGERED),
+// or a resting order accepting new terms (Amended)
+type OrderAcceptedEvent struct {
+	EventHeader
+	Order   *Order `json:"order"`
+	Amended bool   `json:"amended,omitempty"`
+}
+
+// OrderRejectedEvent reports an order refused by its book (basic
+// validation, symbol status, trading rules, price band, FOK). Only orders
+// on unknown symbols, which have no book, are rejected to the caller alone.
+type OrderRejectedEvent struct {
+	EventHeader
+	Order  *Order    `json:"order"`
+	Code   ErrorCode `json:"code,omitempty"`
+	Reason string    `json:"reason"`
+}
+
+// TradeEvent reports an executed trade
+type TradeEvent struct {
+	EventHeader
+	Trade *Trade `json:"trade"`
+}
+
+// OrderFilledEvent reports a fill: Order.Status is PARTIALLY_FILLED or FILLED
+type OrderFilledEvent struct {
+	EventHeader
+	Order *Order `json:"order"`
+}
+
+// OrderCancelledEvent reports a cancel, with the cause in
+// Order.StatusReason. A self-trade decrement cancels only part of an order,
+// which then stays open.
+type OrderCancelledEvent struct {
+	EventHeader
+	Order *Order `json:"order"`
+}
+
+// BookDeltaEvent lists the price levels a command changed, with their new
+// totals. Applied in sequence order on top of a book snapshot with
+// last_update_id N (deltas with Sequence > N), it keeps a copy of the book.
+type BookDeltaEvent struct {
+	EventHeader
+	Bids []LevelDelta `json:"bids"` // Best price first
+	Asks []LevelDelta `json:"asks"`
+}
+
+// LevelDelta is the new state of one price level; zero quantity removes it
+type LevelDelta struct {
+	Price    decimal.Decimal `json:"price"`
+	Quantity decimal.Decimal `json:"quantity"`
+	Orders   int             `json:"orders"`
+}
+
+// SymbolStatusChangedEvent reports a symbol status change
+type SymbolStatusChangedEvent struct {
+	EventHeader
+	OldStatus SymbolStatus `json:"old_status"`
+	NewStatus SymbolStatus `json:"new_status"`
+	Reason    string       `json:"reason"`
+}
+
+// OrderOf returns the order an order event carries, or nil for other events
+func OrderOf(event Event) *Order {
+	switch e := event.(type) {
+	case *OrderAcceptedEvent:
+		return e.Order
+	case *OrderRejectedEvent:
+		return e.Order
+	case *OrderFilledEvent:
+		return e.Order
+	case *OrderCancelledEvent:
+		return e.Order
+	}
+	return nil
+}
+
+// ============================================================================
+// SUBSCRIPTIONS
+// ============================================================================
+
+// EventSink receives engine events, one at a time and in sequence order
+type EventSink interface {
+	HandleEvent(event Event)
+}
+
+// EventSinkFunc adapts a function to EventSink
+type EventSinkFunc func(event Event)
+
+func (f EventSinkFunc) HandleEvent(event Event) { f(event) }
+
+// BackpressurePolicy decides what publishing does when a subscriber's queue
+// is full
+type BackpressurePolicy string
+
+const (
+	BackpressureBlock      BackpressurePolicy = "block"       // matching waits for the subscriber: nothing is lost
+	BackpressureDropOldest BackpressurePolicy = "drop_oldest" // oldest queued event is dropped
+	BackpressureDropNewest BackpressurePolicy = "drop_newest" // the new event is dropped
+	BackpressureDisconnect BackpressurePolicy = "disconnect"  // the subscription is closed (Err reports why)
+)
+
+// DefaultEventBufferSize bounds the events queued per subscriber
+const DefaultEventBufferSize = 1024
+
+// ErrSubscriberTooSlow ends a BackpressureDisconnect subscription whose
+// queue filled up
+var ErrSubscriberTooSlow = errors.New("event subscriber too slow")
+
+// SubscriptionConfig tunes delivery to one subscriber
+type SubscriptionConfig struct {
+	BufferSize   int                // Queued events (0 = DefaultEventBufferSize)
+	Backpressure BackpressurePolicy // Full queue behaviour ("" = block)
+}
+
+// Subscription delivers events to one sink from its own goroutine. Dropped
+// events show up as sequence gaps.
+type Subscription struct {
+	sink   EventSink
+	policy BackpressurePolicy
+	bus    *eventBus
+
+	events chan Event    // Sent to in ticket order, closed after the last ticket
+	quit   chan struct{} // Closed by Close: releases a blocked publisher
+	done   chan struct{} // Closed once delivery stopped
+	once   sync.Once
+
+	tickets uint64 // Events handed out to publishers, under bus.mu
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	turn      uint64 // Ticket of the next event to queue
+	last      uint64 // Tickets handed out before removal
+	removed   bool
+	published uint64 // Events queued or dropped
+	handled   uint64 // Events delivered or dropped
+	dropped   uint64
+	stopped   bool
+	err       error
+}
+
+// eventBus orders events and fans them out. Sequence numbers and per
+// subscriber tickets are allocated under mu; events are queued outside it,
+// in ticket order, so every subscriber sees events in GlobalSequence order
+// and a blocked subscriber does not hold up the bus.
+type eventBus struct {
+	mu     sync.Mutex
+	subs   []*Subscription
+	closed bool
+}
+
+// Subscribe delivers every event published from now on to sink
+func (me *MatchingEngine) Subscribe(sink EventSink, cfg SubscriptionConfig) (*Subscription, error) {
+	policy := cfg.Backpressure
+	switch policy {
+	case "":
+		policy = BackpressureBlock
+	case BackpressureBlock, BackpressureDropOldest, BackpressureDropNewest, BackpressureDisconnect:
+	default:
+		return nil, fmt.Errorf("invalid backpressure policy: %s", policy)
+	}
+
+	size := cfg.BufferSize
+	if size <= 0 {
+		size = DefaultEventBufferSize
+	}
+
+	s := &Subscription{
+		sink:   sink,
+		policy: policy,
+		bus:    &me.bus,
+		events: make(chan Event, size),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+
+	me.bus.mu.Lock()
+	defer me.bus.mu.Unlock()
+
+	if me.bus.closed {
+		return nil, ErrBookClosed
+	}
+	me.bus.subs = append(me.bus.subs, s)
+	go s.run()
+	return s, nil
+}
+
+func (s *Subscription) run() {
+	defer s.finish()
+
+	for event := range s.events {
+		s.sink.HandleEvent(event)
+
+		s.mu.Lock()
+		s.handled++
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	}
+}
+
+func (s *Subscription) finish() {
+	s.mu.Lock()
+	s.stopped = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	close(s.done)
+}
+
+// deliver waits for ticket's turn and queues the event. false means the
+// subscription must be disconnected.
+func (s *Subscription) deliver(event Event, ticket uint64) bool {
+	s.mu.Lock()
+	for s.turn != ticket {
+		s.cond.Wait()
+	}
+	disconnected := s.err != nil
+	s.mu.Unlock()
+
+	ok := disconnected || s.offer(event)
+
+	s.mu.Lock()
+	s.turn++
+	if s.removed && s.turn == s.last {
+		close(s.events)
+	}
+	s.cond.Broadcast()
+	s.mu.Unlock()
+	return ok
+}
+
+// offer queues an event, applying the backpressure policy when the queue is
+// full. false means the subscription must be disconnected. Called in ticket
+// order.
+func (s *Subscription) offer(event Event) bool {
+	s.count(1, 0)
+
+	select {
+	case s.events <- event:
+		return true
+	default:
+	}
+
+	switch s.policy {
+	case BackpressureDropNewest:
+		s.count(0, 1)
+
+	case BackpressureDropOldest:
+		select {
+		case <-s.events:
+			s.count(0, 1)
+		default:
+		}
+		select {
+		case s.events <- event:
+		default:
+			s.count(0, 1)
+		}
+
+	case BackpressureDisconnect:
+		s.count(0, 1)
+		s.mu.Lock()
+		s.err = ErrSubscriberTooSlow
+		s.mu.Unlock()
+		return false
+
+	default:
+		select {
+		case s.events <- event:
+		case <-s.quit:
+			s.count(0, 1)
+		}
+	}
+	return true
+}
+
+// count records published and dropped events
+func (s *Subscription) count(published, dropped uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.published += published
+	s.handled += dropped
+	s.dropped += dropped
+	if dropped > 0 {
+		s.cond.Broadcast()
+	}
+}
+
+// Flush waits until every event published before the call has been handled
+// or dropped, or delivery has stopped. Must not be called from the sink.
+func (s *Subscription) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target := s.published
+	for s.handled < target && !s.stopped {
+		s.cond.Wait()
+	}
+}
+
+// Close unsubscribes. Events already queued are still delivered; Close
+// returns after the last one. Must not be called from the sink.
+func (s *Subscription) Close() {
+	s.once.Do(func() { close(s.quit) })
+
+	s.bus.mu.Lock()
+	s.bus.remove(s)
+	s.bus.mu.Unlock()
+
+	<-s.done
+}
+
+// Done is closed once delivery has stopped: after Close, a disconnect or
+// the engine closing
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why delivery stopped early (ErrSubscriberTooSlow), or nil
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Dropped returns the number of events dropped by the backpressure policy
+func (s *Subscription) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// remove ends delivery to s once its queue is drained. Called under mu.
+func (b *eventBus) remove(s *Subscription) {
+	for i, sub := range b.subs {
+		if sub == s {
+			b.subs = append(b.subs[:i], b.subs[i+1:]...)
+			s.stop()
+			return
+		}
+	}
+}
+
+// stop closes the queue after the events of the tickets already handed out.
+// Called under bus.mu, once.
+func (s *Subscription) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removed, s.last = true, s.tickets
+	if s.turn == s.last {
+		close(s.events)
+	}
+}
+
+// close ends every subscription and waits for their queues to drain
+func (b *eventBus) close() {
+	b.mu.Lock()
+	subs := b.subs
+	b.subs = nil
+	b.closed = true
+	for _, s := range subs {
+		s.stop()
+	}
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		<-s.done
+	}
+}
+
+// ============================================================================
+// PUBLISHING
+// ============================================================================
+
+// fanout is a subscriber's place in the delivery of one event
+type fanout struct {
+	sub    *Subscription
+	ticket uint64
+}
+
+// publish stamps the event built for the book's next sequence numbers and
+// queues it for every subscriber. Only stamping and ticketing hold bus.mu,
+// so a subscriber that blocks stalls the events queued to it, not the bus.
+// Runs on the book's goroutine.
+func (me *MatchingEngine) publish(ob *OrderBook, typ EventType, build func(h EventHeader) Event) {
+	me.bus.mu.Lock()
+	event := build(EventHeader{
+		Type:          typ,
+		Symbol:        ob.Symbol,
+		Timestamp:     ob.commandTime(),
+		EventSequence: me.nextSequence(ob),
+	})
+
+	subs := make([]fanout, len(me.bus.subs))
+	for i, s := range me.bus.subs {
+		subs[i] = fanout{sub: s, ticket: s.tickets}
+		s.tickets++
+	}
+	me.bus.mu.Unlock()
+
+	for _, f := range subs {
+		if !f.sub.deliver(event, f.ticket) {
+			me.bus.mu.Lock()
+			me.bus.remove(f.sub)
+			me.bus.mu.Unlock()
+		}
+	}
+}
+
+// stampOrder records an order event's sequence on the order and returns the
+// copy the event carries
+func stampOrder(order *Order, h EventHeader) *Order {
+	order.EventSequence = h.EventSequence
+	return copyOrder(order)
+}
+
+func (me *MatchingEngine) emitOrderAccepted(ob *OrderBook, order *Order, amended bool) {
+	me.publish(ob, EventOrderAccepted, func(h EventHeader) Event {
+		return &OrderAcceptedEvent{EventHeader: h, Order: stampOrder(order, h), Amended: amended}
+	})
+}
+
+func (me *MatchingEngine) emitOrderRejected(ob *OrderBook, order *Order, err error) {
+	me.publish(ob, EventOrderRejected, func(h EventHeader) Event {
+		event := &OrderRejectedEvent{EventHeader: h, Order: stampOrder(order, h)}
+		if err != nil {
+			event.Code, event.Reason = ErrorCodeOf(err), err.Error()
+		}
+		return event
+	})
+}
+
+func (me *MatchingEngine) emitOrderFilled(ob *OrderBook, order *Order) {
+	me.publish(ob, EventOrderFilled, func(h EventHeader) Event {
+		return &OrderFilledEvent{EventHeader: h, Order: stampOrder(order, h)}
+	})
+}
+
+func (me *MatchingEngine) emitOrderCancelled(ob *OrderBook, order *Order) {
+	me.publish(ob, EventOrderCancelled, func(h EventHeader) Event {
+		return &OrderCancelledEvent{EventHeader: h, Order: stampOrder(order, h)}
+	})
+}
+
+// emitOrderResult reports how an incoming order ended up after matching.
+// An order resting without fills was already reported as accepted.
+func (me *MatchingEngine) emitOrderResult(ob *OrderBook, order *Order) {
+	switch order.Status {
+	case OrderStatusFilled, OrderStatusPartiallyFilled:
+		me.emitOrderFilled(ob, order)
+	case OrderStatusCancelled:
+		me.emitOrderCancelled(ob, order)
+	}
+}
+
+func (me *MatchingEngine) emitTrade(ob *OrderBook, trade *Trade) {
+	me.publish(ob, EventTrade, func(h EventHeader) Event {
+		trade.EventSequence = h.EventSequence
+		copied := *trade
+		return &TradeEvent{EventHeader: h, Trade: &copied}
+	})
+}
+
+// ============================================================================
+// BOOK DELTAS
+// ============================================================================
+
+// levelChange is a price level touched by the running command
+type levelChange struct {
+	side  Side
+	level *PriceLevel
+}
+
+// touch records that a command changed level. Runs on the book's goroutine.
+func (ob *OrderBook) touch(side Side, level *PriceLevel) {
+	ob.changed = append(ob.changed, levelChange{side: side, level: level})
+}
+
+// emitBookDelta reports the levels changed by the command that just ran.
+// Runs on the book's goroutine, after every command.
+func (me *MatchingEngine) emitBookDelta(ob *OrderBook) {
+	if len(ob.changed) == 0 {
+		return
+	}
+
+	bids, asks := make([]LevelDelta, 0), make([]LevelDelta, 0)
+	ob.mu.RLock()
+	for _, change := range ob.changed {
+		delta := LevelDelta{Price: change.level.Price, Quantity: decimal.Zero}
+		if live := ob.liveLevel(change.side, change.level); live != nil {
+			delta.Quantity, delta.Orders = live.Quantity, live.Len()
+		}
+		if change.side == SideBuy {
+			bids = append(bids, delta)
+		} else {
+			asks = append(asks, delta)
+		}
+	}
+	ob.mu.RUnlock()
+	ob.changed = ob.changed[:0]
+
+	bids = sortDeltas(bids, func(a, b decimal.Decimal) bool { return a.GreaterThan(b) })
+	asks = sortDeltas(asks, func(a, b decimal.Decimal) bool { return a.LessThan(b) })
+
+	me.publish(ob, EventBookDelta, func(h EventHeader) Event {
+		return &BookDeltaEvent{EventHeader: h, Bids: bids, Asks: asks}
+	})
+}
+
+// sortDeltas orders deltas best price first and drops repeats: a level
+// touched several times in a command is reported once
+func sortDeltas(deltas []LevelDelta, better func(a, b decimal.Decimal) bool) []LevelDelta {
+	sort.SliceStable(deltas, func(i, j int) bool { return better(deltas[i].Price, deltas[j].Price) })
+
+	unique := deltas[:0]
+	for _, delta := range deltas {
+		if n := len(unique); n > 0 && unique[n-1].Price.Equal(delta.Price) {
+			continue
+		}
+		unique = append(unique, delta)
+	}
+	return unique
+}
+
+// liveLevel returns the level currently in the book at level's price
+func (ob *OrderBook) liveLevel(side Side, level *PriceLevel) *PriceLevel {
+	if ob.fx != nil {
+		return ob.sideQueue(side).getTicks(level.fxPrice)
+	}
+	return ob.sideQueue(side).Get(level.Price)
+}
+
+// BookLevels is a copy of every price level of a book: the base that the
+// book deltas with Sequence > LastUpdateID apply to
+type BookLevels struct {
+	Bids         []LevelDelta // Best price first
+	Asks         []LevelDelta
+	LastPrice    decimal.Decimal
+	LastUpdateID uint64 // Per-symbol sequence of the latest event
+}
+
+// ReadBookLevels copies every price level of a book on the book's goroutine
+func (me *MatchingEngine) ReadBookLevels(symbol string) (BookLevels, bool) {
+	var levels BookLevels
+	ok := me.ReadOrderBook(symbol, func(ob *OrderBook) {
+		ob.mu.RLock()
+		defer ob.mu.RUnlock()
+
+		levels = BookLevels{
+			Bids:         levelsOf(ob.Bids),
+			Asks:         levelsOf(ob.Asks),
+			LastPrice:    ob.LastPrice,
+			LastUpdateID: ob.seq,
+		}
+	})
+	return levels, ok
+}
+
+func levelsOf(queue *PriceQueue) []LevelDelta {
+	levels := make([]LevelDelta, 0, queue.Len())
+	queue.Each(func(level *PriceLevel) bool {
+		levels = append(levels, LevelDelta{Price: level.Price, Quantity: level.Quantity, Orders: level.Len()})
+		return true
+	})
+	return levels
+}
@@ -0,0 +1,378 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - EVENT BUS TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventRecorder collects the events delivered to one subscriber
+type eventRecorder struct {
+	sub    *Subscription
+	mu     sync.Mutex
+	events []Event
+}
+
+func recordEvents(t *testing.T, me *MatchingEngine) *eventRecorder {
+	r := &eventRecorder{}
+	sub, err := me.Subscribe(EventSinkFunc(func(event Event) {
+		r.mu.Lock()
+		r.events = append(r.events, event)
+		r.mu.Unlock()
+	}), SubscriptionConfig{})
+	require.NoError(t, err)
+
+	r.sub = sub
+	t.Cleanup(sub.Close)
+	return r
+}
+
+// all returns every event published so far
+func (r *eventRecorder) all() []Event {
+	r.sub.Flush()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+func (r *eventRecorder) types() []EventType {
+	types := make([]EventType, 0)
+	for _, event := range r.all() {
+		types = append(types, event.Header().Type)
+	}
+	return types
+}
+
+// orders returns the order of every order event
+func (r *eventRecorder) orders() []*Order {
+	orders := make([]*Order, 0)
+	for _, event := range r.all() {
+		if order := OrderOf(event); order != nil {
+			orders = append(orders, order)
+		}
+	}
+	return orders
+}
+
+func (r *eventRecorder) trades() []*Trade {
+	trades := make([]*Trade, 0)
+	for _, event := range r.all() {
+		if e, ok := event.(*TradeEvent); ok {
+			trades = append(trades, e.Trade)
+		}
+	}
+	return trades
+}
+
+func (r *eventRecorder) breakers() []*CircuitBreakerEvent {
+	events := make([]*CircuitBreakerEvent, 0)
+	for _, event := range r.all() {
+		if e, ok := event.(*CircuitBreakerEvent); ok {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func (r *eventRecorder) deltas() []*BookDeltaEvent {
+	events := make([]*BookDeltaEvent, 0)
+	for _, event := range r.all() {
+		if e, ok := event.(*BookDeltaEvent); ok {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func TestEventBus_OrderLifecycle(t *testing.T) {
+	me, _ := newTestClockEngine()
+	events := recordEvents(t, me)
+
+	ask := newTestOrder(SideSell, OrderTypeLimit, "1.0", "50000")
+	_, err := me.PlaceOrder(ask)
+	require.NoError(t, err)
+	_, err = me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "0.4", "50000"))
+	require.NoError(t, err)
+	require.NoError(t, me.CancelOrder(ask.OrderID, ask.Symbol, ask.UserID))
+
+	fok := newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000")
+	fok.TimeInForce = TimeInForceFOK
+	_, err = me.PlaceOrder(fok)
+	assert.Error(t, err)
+
+	invalid := newTestOrder(SideBuy, OrderTypeLimit, "0", "50000")
+	_, err = me.PlaceOrder(invalid)
+	assert.Error(t, err)
+
+	assert.Equal(t, []EventType{
+		EventOrderAccepted, EventBookDelta, // Ask rests
+		EventOrderAccepted, EventOrderFilled, EventTrade, EventOrderFilled, EventBookDelta, // Bid trades
+		EventOrderCancelled, EventBookDelta, // Ask cancelled
+		EventOrderRejected, // FOK killed without touching the book
+		EventOrderRejected, // Invalid quantity
+	}, events.types())
+
+	all := events.all()
+	for i, event := range all {
+		assert.Equal(t, uint64(i+1), event.Header().Sequence)
+		assert.Equal(t, "BTC/USDT", event.Header().Symbol)
+		assert.Equal(t, testEpoch, event.Header().Timestamp)
+	}
+
+	// Order events carry copies taken when they were published
+	accepted := all[0].(*OrderAcceptedEvent)
+	assert.Equal(t, OrderStatusOpen, accepted.Order.Status)
+	assert.Equal(t, OrderStatusCancelled, ask.Status)
+	assert.Equal(t, uint64(8), ask.Sequence)
+
+	partial := all[3].(*OrderFilledEvent)
+	assert.Equal(t, ask.OrderID, partial.Order.OrderID)
+	assert.Equal(t, OrderStatusPartiallyFilled, partial.Order.Status)
+	assert.Equal(t, "0.4", partial.Order.FilledQuantity.String())
+
+	trade := all[4].(*TradeEvent)
+	assert.Equal(t, "trade-1", trade.Trade.TradeID)
+	assert.Equal(t, uint64(5), trade.Trade.Sequence)
+
+	rejected := all[9].(*OrderRejectedEvent)
+	assert.Equal(t, fok.OrderID, rejected.Order.OrderID)
+	assert.Equal(t, ErrCodeFillOrKill, rejected.Code)
+	assert.Equal(t, "FOK order could not be filled completely", rejected.Reason)
+
+	rejected = all[10].(*OrderRejectedEvent)
+	assert.Equal(t, invalid.OrderID, rejected.Order.OrderID)
+	assert.Equal(t, OrderStatusRejected, rejected.Order.Status)
+	assert.Equal(t, "quantity must be positive", rejected.Reason)
+}
+
+func TestEventBus_BookDelta(t *testing.T) {
+	me := newTestEngine()
+	events := recordEvents(t, me)
+
+	for _, order := range []*Order{
+		newTestOrder(SideSell, OrderTypeLimit, "1.0", "50000"),
+		newTestOrder(SideSell, OrderTypeLimit, "2.0", "50000"),
+		newTestOrder(SideSell, OrderTypeLimit, "1.0", "50100"),
+		newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49900"),
+	} {
+		_, err := me.PlaceOrder(order)
+		require.NoError(t, err)
+	}
+
+	// A buy sweeping both ask levels, resting the rest at 50200
+	_, err := me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "4.5", "50200"))
+	require.NoError(t, err)
+
+	deltas := events.deltas()
+	require.Equal(t, 5, len(deltas))
+	assert.Equal(t, "50000", deltas[1].Asks[0].Price.String())
+	assert.Equal(t, "3", deltas[1].Asks[0].Quantity.String())
+	assert.Equal(t, 2, deltas[1].Asks[0].Orders)
+
+	sweep := deltas[4]
+	require.Equal(t, 1, len(sweep.Bids))
+	assert.Equal(t, "50200", sweep.Bids[0].Price.String())
+	assert.Equal(t, "0.5", sweep.Bids[0].Quantity.String())
+	require.Equal(t, 2, len(sweep.Asks))
+	assert.Equal(t, "50000", sweep.Asks[0].Price.String())
+	assert.True(t, sweep.Asks[0].Quantity.IsZero()) // Level removed
+	assert.Equal(t, "50100", sweep.Asks[1].Price.String())
+	assert.True(t, sweep.Asks[1].Quantity.IsZero())
+
+	// Replaying the deltas rebuilds the book depth
+	book := map[Side]map[string]string{SideBuy: {}, SideSell: {}}
+	for _, delta := range deltas {
+		for side, levels := range map[Side][]LevelDelta{SideBuy: delta.Bids, SideSell: delta.Asks} {
+			for _, level := range levels {
+				if level.Quantity.IsZero() {
+					delete(book[side], level.Price.String())
+				} else {
+					book[side][level.Price.String()] = level.Quantity.String()
+				}
+			}
+		}
+	}
+	assert.Equal(t, map[string]string{"50200": "0.5", "49900": "1"}, book[SideBuy])
+	assert.Empty(t, book[SideSell])
+}
+
+func TestEventBus_SubscribersSeeGlobalOrder(t *testing.T) {
+	me := newTestEngine()
+	defer me.Close()
+	first, second := recordEvents(t, me), recordEvents(t, me)
+
+	var wg sync.WaitGroup
+	for _, symbol := range []string{"BTC/USDT", "ETH/USDT", "BNB/USDT"} {
+		wg.Add(1)
+		go func(symbol string) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				order := newTestOrder(SideBuy, OrderTypeLimit, "1.0", fmt.Sprintf("%d", 49000+i%5))
+				if i%2 == 1 {
+					order.Side = SideSell
+				}
+				order.Symbol = symbol
+				me.PlaceOrder(order)
+			}
+		}(symbol)
+	}
+	wg.Wait()
+
+	events := first.all()
+	assert.Equal(t, events, second.all())
+	require.Equal(t, me.GlobalSequence(), uint64(len(events)))
+
+	perSymbol := make(map[string]uint64)
+	for i, event := range events {
+		h := event.Header()
+		assert.Equal(t, uint64(i+1), h.GlobalSequence)
+		assert.Equal(t, perSymbol[h.Symbol]+1, h.Sequence)
+		perSymbol[h.Symbol] = h.Sequence
+	}
+}
+
+// blockingSink holds the first event until released, so the events after
+// it pile up in the subscriber's queue
+type blockingSink struct {
+	entered chan struct{}
+	release chan struct{}
+	mu      sync.Mutex
+	seqs    []uint64
+}
+
+func newBlockingSink() *blockingSink {
+	return &blockingSink{entered: make(chan struct{}, 1), release: make(chan struct{})}
+}
+
+func (s *blockingSink) HandleEvent(event Event) {
+	select {
+	case s.entered <- struct{}{}:
+	default:
+	}
+	<-s.release
+
+	s.mu.Lock()
+	s.seqs = append(s.seqs, event.Header().Sequence)
+	s.mu.Unlock()
+}
+
+func TestEventBus_Backpressure(t *testing.T) {
+	tests := []struct {
+		policy  BackpressurePolicy
+		want    []uint64
+		dropped uint64
+		err     error
+	}{
+		{BackpressureBlock, []uint64{1, 2, 3, 4}, 0, nil},
+		{BackpressureDropNewest, []uint64{1, 2, 3}, 1, nil},
+		{BackpressureDropOldest, []uint64{1, 3, 4}, 1, nil},
+		{BackpressureDisconnect, []uint64{1, 2, 3}, 1, ErrSubscriberTooSlow},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			me := newTestEngine()
+			defer me.Close()
+
+			sink := newBlockingSink()
+			sub, err := me.Subscribe(sink, SubscriptionConfig{BufferSize: 2, Backpressure: tt.policy})
+			require.NoError(t, err)
+
+			// Event 1 is being handled, event 2 is queued
+			_, err = me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49000"))
+			require.NoError(t, err)
+			<-sink.entered
+
+			// Events 3 and 4 overflow the queue
+			future := me.SubmitOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49100"))
+			if tt.policy == BackpressureBlock {
+				select {
+				case <-future.Done():
+					t.Fatal("publishing did not wait for the subscriber")
+				case <-time.After(20 * time.Millisecond):
+				}
+				close(sink.release)
+				_, err = future.Wait()
+			} else {
+				_, err = future.Wait()
+				close(sink.release)
+			}
+			require.NoError(t, err)
+			sub.Flush()
+
+			sink.mu.Lock()
+			assert.Equal(t, tt.want, sink.seqs)
+			sink.mu.Unlock()
+			assert.Equal(t, tt.dropped, sub.Dropped())
+			assert.Equal(t, tt.err, sub.Err())
+		})
+	}
+}
+
+func TestEventBus_BlockedSubscriberReleasesBus(t *testing.T) {
+	me := newTestEngine()
+	defer me.Close()
+	fast := recordEvents(t, me)
+
+	sink := newBlockingSink()
+	slow, err := me.Subscribe(sink, SubscriptionConfig{BufferSize: 1})
+	require.NoError(t, err)
+
+	// Event 1 is being handled, event 2 is queued and event 3 waits for room
+	_, err = me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49000"))
+	require.NoError(t, err)
+	<-sink.entered
+	future := me.SubmitOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49100"))
+
+	// Subscribers before the slow one get event 3, and the bus stays usable
+	assert.Eventually(t, func() bool { return len(fast.all()) == 3 }, time.Second, time.Millisecond)
+	subscribed := make(chan struct{})
+	go func() {
+		defer close(subscribed)
+		recordEvents(t, me)
+	}()
+	select {
+	case <-subscribed:
+	case <-time.After(time.Second):
+		t.Fatal("subscribing waited for the blocked subscriber")
+	}
+
+	close(sink.release)
+	_, err = future.Wait()
+	require.NoError(t, err)
+	slow.Flush()
+
+	sink.mu.Lock()
+	assert.Equal(t, []uint64{1, 2, 3, 4}, sink.seqs)
+	sink.mu.Unlock()
+	slow.Close()
+}
+
+func TestEventBus_Close(t *testing.T) {
+	me := newTestEngine()
+
+	_, err := me.Subscribe(EventSinkFunc(func(Event) {}), SubscriptionConfig{Backpressure: "sometimes"})
+	assert.Error(t, err)
+
+	events := recordEvents(t, me)
+	_, err = me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49000"))
+	require.NoError(t, err)
+
+	// Closing the engine delivers what was published, then ends delivery
+	me.Close()
+	<-events.sub.Done()
+	assert.Equal(t, 2, len(events.all()))
+	assert.NoError(t, events.sub.Err())
+
+	_, err = me.Subscribe(EventSinkFunc(func(Event) {}), SubscriptionConfig{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,461 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - FIXED-POINT MATCHING
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Hot Path Arithmetic)
+// Description: Optional int64 representation of prices and quantities,
+//              scaled by the symbol's tick and step size precision. Spec
+//              checks, fill arithmetic, fees and price-level ordering run
+//              on integers; decimal fields stay the API representation and
+//              are written where results leave the match loop (NFR-002).
+// ============================================================================
+
+package matching
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+
+	"github.com/shopspring/decimal"
+)
+
+// FixedScale converts between decimals and scaled int64 values for a symbol:
+// price = ticks * 10^-PriceDecimals, quantity = lots * 10^-QtyDecimals
+type FixedScale struct {
+	PriceDecimals int32
+	QtyDecimals   int32
+}
+
+// NewFixedScale derives the scale from a spec's tick and step size, so that
+// every valid price and quantity of the symbol is an exact integer
+func NewFixedScale(spec SymbolSpec) (FixedScale, error) {
+	if !spec.TickSize.IsPositive() || !spec.StepSize.IsPositive() {
+		return FixedScale{}, errors.New("fixed-point matching requires tick_size and step_size")
+	}
+
+	return FixedScale{
+		PriceDecimals: decimalPlaces(spec.TickSize),
+		QtyDecimals:   decimalPlaces(spec.StepSize),
+	}, nil
+}
+
+// decimalPlaces returns the number of fractional digits of d (0.01 -> 2)
+func decimalPlaces(d decimal.Decimal) int32 {
+	places := -d.Exponent()
+	for places > 0 && d.Shift(places-1).IsInteger() {
+		places--
+	}
+	if places < 0 {
+		return 0
+	}
+	return places
+}
+
+// Ticks converts a price to ticks; false if it isn't exactly representable
+func (s FixedScale) Ticks(price decimal.Decimal) (int64, bool) {
+	return toFixed(price, s.PriceDecimals)
+}
+
+// Lots converts a quantity to lots; false if it isn't exactly representable
+func (s FixedScale) Lots(quantity decimal.Decimal) (int64, bool) {
+	return toFixed(quantity, s.QtyDecimals)
+}
+
+// Price converts ticks back to a decimal price
+func (s FixedScale) Price(ticks int64) decimal.Decimal {
+	return decimal.New(ticks, -s.PriceDecimals)
+}
+
+// Quantity converts lots back to a decimal quantity
+func (s FixedScale) Quantity(lots int64) decimal.Decimal {
+	return decimal.New(lots, -s.QtyDecimals)
+}
+
+// toFixed scales d by 10^places working on its int64 coefficient, which
+// keeps order entry free of big.Int arithmetic
+func toFixed(d decimal.Decimal, places int32) (int64, bool) {
+	coefficient := d.CoefficientInt64()
+	if !decimal.New(coefficient, d.Exponent()).Equal(d) {
+		return 0, false // Coefficient overflows int64
+	}
+
+	exp := d.Exponent() + places
+	for ; exp < 0; exp++ {
+		if coefficient%10 != 0 {
+			return 0, false // Finer than the scale
+		}
+		coefficient /= 10
+	}
+	for ; exp > 0; exp-- {
+		if coefficient > math.MaxInt64/10 || coefficient < math.MinInt64/10 {
+			return 0, false
+		}
+		coefficient *= 10
+	}
+	return coefficient, true
+}
+
+// convert returns price in ticks and quantity in lots, rejecting values finer
+// than the symbol's precision or outside int64
+func (s FixedScale) convert(price, quantity decimal.Decimal) (int64, int64, error) {
+	ticks, ok := s.Ticks(price)
+	if !ok {
+		return 0, 0, newOrderError(ErrCodeInvalidTickSize,
+			"price %s exceeds the symbol's fixed-point precision", price)
+	}
+
+	lots, ok := s.Lots(quantity)
+	if !ok {
+		return 0, 0, newOrderError(ErrCodeInvalidStepSize,
+			"quantity %s exceeds the symbol's fixed-point precision", quantity)
+	}
+	return ticks, lots, nil
+}
+
+// load computes the order's fixed-point mirrors from its decimal fields
+func (s FixedScale) load(order *Order) error {
+	price, quantity, err := s.convert(order.Price, order.Quantity)
+	if err != nil {
+		return err
+	}
+
+	// Never more precise than Quantity, so always exact
+	filled, _ := s.Lots(order.FilledQuantity)
+
+	order.fxPrice, order.fxQty, order.fxFilled = price, quantity, filled
+	return nil
+}
+
+// ============================================================================
+// ENGINE INTEGRATION
+// ============================================================================
+
+// SetFixedPoint switches a symbol between decimal and fixed-point matching.
+// The scale is taken from the symbol's current spec, so call it after
+// SetSymbolSpec; the book must have no resting or stop orders.
+func (me *MatchingEngine) SetFixedPoint(symbol string, enabled bool) error {
+	ob, ok := me.GetOrderBook(symbol)
+	if !ok {
+		return errUnknownSymbol(symbol)
+	}
+
+	return ob.do(func() error {
+		ob.mu.Lock()
+		defer ob.mu.Unlock()
+
+		if len(ob.Orders) > 0 || len(ob.StopOrders) > 0 {
+			return errors.New("cannot change matching representation of a non-empty book")
+		}
+
+		if !enabled {
+			ob.fx = nil
+			ob.Bids.fixed, ob.Asks.fixed = false, false
+			return nil
+		}
+
+		scale, err := NewFixedScale(ob.Spec)
+		if err != nil {
+			return err
+		}
+
+		ob.fx = &scale
+		ob.fxRules = scale.rules(ob.Spec)
+		ob.Bids.fixed, ob.Asks.fixed = true, true
+		return nil
+	})
+}
+
+// loadFixed prepares an order entering a fixed-point book. Runs on the
+// book's goroutine.
+func (ob *OrderBook) loadFixed(order *Order) error {
+	if ob.fx == nil {
+		return nil
+	}
+	return ob.fx.load(order)
+}
+
+// fixedRules is a SymbolSpec in ticks and lots, so that orders entering a
+// fixed-point book are validated on integers. valid is false when a limit
+// doesn't fit the scale; such books validate on decimals.
+type fixedRules struct {
+	valid       bool
+	tick, step  int64 // 0: no increment
+	minLots     int64
+	maxLots     int64 // math.MaxInt64: no maximum
+	minNotional int64 // In ticks * lots; 0: no minimum
+}
+
+// rules converts spec's limits, rounding them inwards
+func (s FixedScale) rules(spec SymbolSpec) fixedRules {
+	tick, tickOK := s.Ticks(spec.TickSize)
+	step, stepOK := s.Lots(spec.StepSize)
+	minLots, minOK := toFixed(spec.MinQuantity.Shift(s.QtyDecimals).Ceil(), 0)
+	minNotional, notionalOK := toFixed(spec.MinNotional.Shift(s.PriceDecimals+s.QtyDecimals).Ceil(), 0)
+
+	maxLots, maxOK := int64(math.MaxInt64), true
+	if spec.MaxQuantity.IsPositive() {
+		maxLots, maxOK = toFixed(spec.MaxQuantity.Shift(s.QtyDecimals).Floor(), 0)
+	}
+
+	return fixedRules{
+		valid:       tickOK && stepOK && minOK && maxOK && notionalOK,
+		tick:        tick,
+		step:        step,
+		minLots:     minLots,
+		maxLots:     maxLots,
+		minNotional: minNotional,
+	}
+}
+
+// validateSpec checks a loaded order against the symbol's trading rules.
+// Fixed-point books check limit and market orders on ticks and lots, and
+// only build the decimal error of a rule that failed.
+func (ob *OrderBook) validateSpec(order *Order) error {
+	ob.mu.RLock()
+	spec, rules := ob.Spec, ob.fxRules
+	ob.mu.RUnlock()
+
+	// Stop orders are not matched on entry: they keep the decimal checks
+	if ob.fx == nil || !rules.valid || order.StopPrice.IsPositive() {
+		return spec.ValidateOrder(order)
+	}
+
+	if rules.tick > 0 && order.fxPrice%rules.tick != 0 {
+		return spec.checkPrice(order.Price)
+	}
+
+	lots := order.fxQty
+	if (rules.step > 0 && lots%rules.step != 0) || lots < rules.minLots || lots > rules.maxLots {
+		return spec.checkQuantity(order.Quantity)
+	}
+
+	if rules.minNotional > 0 && order.fxPrice > 0 {
+		if notional, ok := mulFixed(order.fxPrice, lots); ok && notional < rules.minNotional {
+			return spec.checkNotional(order.Price, order.Quantity)
+		}
+	}
+	return nil
+}
+
+// checkFixed verifies an amended price and quantity fit the book's scale
+func (ob *OrderBook) checkFixed(price, quantity decimal.Decimal) error {
+	if ob.fx == nil {
+		return nil
+	}
+	_, _, err := ob.fx.convert(price, quantity)
+	return err
+}
+
+// syncFixed refreshes fixed-point mirrors after a decimal-side change
+// (self-trade decrement, in-place amend)
+func (ob *OrderBook) syncFixed(level *PriceLevel, orders ...*Order) {
+	if ob.fx == nil {
+		return
+	}
+
+	for _, order := range orders {
+		ob.fx.load(order)
+	}
+	if level != nil {
+		level.fxQuantity, _ = ob.fx.Lots(level.Quantity)
+	}
+}
+
+// matchQuantity is a quantity on the matching hot path: lots in fixed-point
+// books, a decimal otherwise
+type matchQuantity struct {
+	lots  int64
+	value decimal.Decimal
+	fixed bool
+}
+
+// IsPositive reports whether any quantity is left
+func (q matchQuantity) IsPositive() bool {
+	if q.fixed {
+		return q.lots > 0
+	}
+	return q.value.IsPositive()
+}
+
+// remainingOf returns order's unfilled quantity in the book's representation
+func (ob *OrderBook) remainingOf(order *Order) matchQuantity {
+	if ob.fx != nil {
+		return matchQuantity{lots: order.fxRemaining(), fixed: true}
+	}
+	return matchQuantity{value: order.RemainingQuantity()}
+}
+
+// quantity converts q to the API representation
+func (ob *OrderBook) quantity(q matchQuantity) decimal.Decimal {
+	if q.fixed {
+		return ob.fx.Quantity(q.lots)
+	}
+	return q.value
+}
+
+// applyFill fills taker against maker at level and returns the fill quantity,
+// the taker's remaining quantity and whether the maker is now fully filled.
+//
+// In fixed-point books only the maker's FilledQuantity is written back, as
+// its fill is reported right away; the taker's and the level's decimals are
+// stored once matching is done with them (storeFixed).
+func (ob *OrderBook) applyFill(taker, maker *Order, level *PriceLevel, remaining matchQuantity) (fill, rest matchQuantity, makerFilled bool) {
+	ob.touch(maker.Side, level)
+
+	if ob.fx == nil {
+		fillQty := decimal.Min(remaining.value, maker.RemainingQuantity())
+
+		taker.FilledQuantity = taker.FilledQuantity.Add(fillQty)
+		maker.FilledQuantity = maker.FilledQuantity.Add(fillQty)
+		level.Quantity = level.Quantity.Sub(fillQty)
+
+		return matchQuantity{value: fillQty}, matchQuantity{value: remaining.value.Sub(fillQty)}, maker.IsFilled()
+	}
+
+	lots := remaining.lots
+	if makerRemaining := maker.fxRemaining(); makerRemaining < lots {
+		lots = makerRemaining
+	}
+
+	taker.fxFilled += lots
+	maker.fxFilled += lots
+	level.fxQuantity -= lots
+	ob.storeFixed(nil, maker)
+
+	fill = matchQuantity{lots: lots, fixed: true}
+	rest = matchQuantity{lots: remaining.lots - lots, fixed: true}
+	return fill, rest, maker.fxFilled == maker.fxQty
+}
+
+// storeFixed writes the fixed-point quantities of level and orders back to
+// their decimal fields
+func (ob *OrderBook) storeFixed(level *PriceLevel, orders ...*Order) {
+	if ob.fx == nil {
+		return
+	}
+
+	for _, order := range orders {
+		if order.fxFilled == order.fxQty {
+			order.FilledQuantity = order.Quantity // Same exponent: IsFilled needn't rescale
+		} else {
+			order.FilledQuantity = ob.fx.Quantity(order.fxFilled)
+		}
+	}
+	if level != nil {
+		level.Quantity = ob.fx.Quantity(level.fxQuantity)
+	}
+}
+
+// ============================================================================
+// PRICE LIMITS AND FEES
+// ============================================================================
+
+// limitTicks converts a market order's protection price to ticks, rounded
+// towards the book so that comparing ticks agrees with withinLimit
+func (ob *OrderBook) limitTicks(side Side, limit decimal.Decimal) int64 {
+	if ob.fx == nil || limit.IsZero() {
+		return 0
+	}
+
+	shifted := limit.Shift(ob.fx.PriceDecimals)
+	if side == SideBuy {
+		shifted = shifted.Floor()
+	} else {
+		shifted = shifted.Ceil()
+	}
+
+	ticks, ok := toFixed(shifted, 0)
+	if !ok {
+		return math.MaxInt64 // Beyond any representable price
+	}
+	return ticks
+}
+
+// levelWithin is withinLimit for a price level, on ticks in fixed-point books
+func (ob *OrderBook) levelWithin(side Side, level *PriceLevel, limit decimal.Decimal, limitTicks int64) bool {
+	if ob.fx == nil {
+		return withinLimit(side, level.Price, limit)
+	}
+
+	if side == SideBuy {
+		return level.fxPrice <= limitTicks
+	}
+	return level.fxPrice >= limitTicks
+}
+
+// fixedRate is a fee rate as an int64 coefficient and exponent. The rate it
+// was taken from is kept, so the conversion runs once per rate change.
+type fixedRate struct {
+	rate decimal.Decimal
+	coef int64
+	ok   bool
+}
+
+func (r *fixedRate) load(rate decimal.Decimal) {
+	if r.rate.Exponent() == rate.Exponent() && r.rate.Equal(rate) {
+		return
+	}
+
+	r.rate = rate
+	r.coef, r.ok = toFixed(rate, -rate.Exponent())
+}
+
+// fee returns value * rate, value being in 10^exp units; false if the
+// product leaves int64
+func (r *fixedRate) fee(value int64, exp int32) (decimal.Decimal, bool) {
+	if !r.ok {
+		return decimal.Decimal{}, false
+	}
+
+	coef, ok := mulFixed(value, r.coef)
+	if !ok {
+		return decimal.Decimal{}, false
+	}
+	return decimal.New(coef, exp+r.rate.Exponent()), true
+}
+
+// fees returns the maker and taker fee of a fill at level. Fixed-point books
+// compute them from ticks * lots while the products fit in int64.
+func (ob *OrderBook) fees(level *PriceLevel, fill matchQuantity, makerRate, takerRate decimal.Decimal) (maker, taker decimal.Decimal) {
+	if ob.fx != nil {
+		ob.makerRate.load(makerRate)
+		ob.takerRate.load(takerRate)
+
+		if value, ok := mulFixed(level.fxPrice, fill.lots); ok {
+			exp := -ob.fx.PriceDecimals - ob.fx.QtyDecimals
+			maker, makerOK := ob.makerRate.fee(value, exp)
+			taker, takerOK := ob.takerRate.fee(value, exp)
+			if makerOK && takerOK {
+				return maker, taker
+			}
+		}
+	}
+
+	value := level.Price.Mul(ob.quantity(fill))
+	return value.Mul(makerRate), value.Mul(takerRate)
+}
+
+// mulFixed multiplies two int64 values; false if the product overflows
+func mulFixed(a, b int64) (int64, bool) {
+	if a == math.MinInt64 || b == math.MinInt64 {
+		return 0, false
+	}
+
+	negative := (a < 0) != (b < 0)
+	if a < 0 {
+		a = -a
+	}
+	if b < 0 {
+		b = -b
+	}
+
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	if hi != 0 || lo > math.MaxInt64 {
+		return 0, false
+	}
+	if negative {
+		return -int64(lo), true
+	}
+	return int64(lo), true
+}
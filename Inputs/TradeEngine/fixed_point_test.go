@@ -0,0 +1,215 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - FIXED-POINT MATCHING TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFixedEngine returns an engine with a tick 0.01 / step 0.0001 spec
+// on BTC/USDT, matching on int64 ticks and lots when fixed is set
+func newTestFixedEngine(t testing.TB, fixed bool) *MatchingEngine {
+	spec, err := NewSymbolSpec("0.01", "0.0001", "", "", "", "")
+	require.NoError(t, err)
+
+	me := newTestEngine()
+	me.SetSymbolSpec("BTC/USDT", spec)
+	require.NoError(t, me.SetFixedPoint("BTC/USDT", fixed))
+	return me
+}
+
+func TestFixedScale_Conversion(t *testing.T) {
+	spec, err := NewSymbolSpec("0.50", "0.001", "", "", "", "")
+	require.NoError(t, err)
+
+	scale, err := NewFixedScale(spec)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), scale.PriceDecimals)
+	assert.Equal(t, int32(3), scale.QtyDecimals)
+
+	ticks, ok := scale.Ticks(decimal.RequireFromString("50000.5"))
+	assert.True(t, ok)
+	assert.Equal(t, int64(500005), ticks)
+	assert.Equal(t, "50000.5", scale.Price(ticks).String())
+
+	lots, ok := scale.Lots(decimal.RequireFromString("1.25"))
+	assert.True(t, ok)
+	assert.Equal(t, int64(1250), lots)
+	assert.Equal(t, "1.25", scale.Quantity(lots).String())
+
+	// Finer than the scale, or beyond int64
+	_, ok = scale.Lots(decimal.RequireFromString("0.0001"))
+	assert.False(t, ok)
+	_, ok = scale.Ticks(decimal.RequireFromString("1e30"))
+	assert.False(t, ok)
+
+	// Specs without tick/step size have no scale
+	_, err = NewFixedScale(SymbolSpec{})
+	assert.Error(t, err)
+}
+
+func TestMatchingEngine_SetFixedPoint(t *testing.T) {
+	me := newTestEngine()
+	assert.Error(t, me.SetFixedPoint("BTC/USDT", true))
+
+	me.GetOrCreateOrderBook("BTC/USDT")
+	assert.Error(t, me.SetFixedPoint("BTC/USDT", true), "spec has no tick size")
+
+	me = newTestFixedEngine(t, true)
+	_, err := me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000"))
+	require.NoError(t, err)
+	assert.Error(t, me.SetFixedPoint("BTC/USDT", false), "book is not empty")
+}
+
+func TestMatchingEngine_FixedPoint_MatchesLikeDecimal(t *testing.T) {
+	decimalEngine := newTestFixedEngine(t, false)
+	fixedEngine := newTestFixedEngine(t, true)
+
+	// Same script on both engines: resting orders, partial fills, amend,
+	// a sweep across levels and an IOC remainder
+	script := func(me *MatchingEngine) []*Trade {
+		var trades []*Trade
+		place := func(order *Order) *Order {
+			order.UserID = "user-" + string(order.Side)
+			result, err := me.PlaceOrder(order)
+			require.NoError(t, err)
+			trades = append(trades, result...)
+			return order
+		}
+
+		place(newTestOrder(SideSell, OrderTypeLimit, "0.5", "50000.10"))
+		place(newTestOrder(SideSell, OrderTypeLimit, "0.75", "50000.10"))
+		amended := place(newTestOrder(SideSell, OrderTypeLimit, "2.0", "50001"))
+		place(newTestOrder(SideBuy, OrderTypeLimit, "0.3333", "49999.99"))
+
+		place(newTestOrder(SideBuy, OrderTypeLimit, "0.6", "50000.10"))
+		_, err := me.AmendOrder(amended.OrderID, amended.Symbol, amended.UserID, decimal.Zero, decimal.RequireFromString("1.5"))
+		require.NoError(t, err)
+
+		ioc := newTestOrder(SideBuy, OrderTypeLimit, "3.0", "50001")
+		ioc.TimeInForce = TimeInForceIOC
+		place(ioc)
+		place(newTestMarketOrder(SideSell, "0.1"))
+		return trades
+	}
+
+	expected := script(decimalEngine)
+	actual := script(fixedEngine)
+
+	require.Equal(t, len(expected), len(actual))
+	for i := range expected {
+		assert.True(t, expected[i].Price.Equal(actual[i].Price), "trade %d price", i)
+		assert.True(t, expected[i].Quantity.Equal(actual[i].Quantity), "trade %d quantity", i)
+		assert.True(t, expected[i].BuyerFee.Equal(actual[i].BuyerFee), "trade %d buyer fee", i)
+		assert.True(t, expected[i].SellerFee.Equal(actual[i].SellerFee), "trade %d seller fee", i)
+	}
+
+	expectedBook := decimalEngine.GetOrderBookSnapshot("BTC/USDT", 10)
+	actualBook := fixedEngine.GetOrderBookSnapshot("BTC/USDT", 10)
+	assert.Equal(t, expectedBook["bids"], actualBook["bids"])
+	assert.Equal(t, expectedBook["asks"], actualBook["asks"])
+}
+
+func TestMatchingEngine_FixedPoint_ValidatesLikeDecimal(t *testing.T) {
+	spec, err := NewSymbolSpec("0.05", "0.001", "0.01", "100", "10", "1")
+	require.NoError(t, err)
+
+	newEngine := func(fixed bool) *MatchingEngine {
+		me := newTestEngine()
+		require.NoError(t, me.SetSymbolSpec("BTC/USDT", spec))
+		require.NoError(t, me.SetFixedPoint("BTC/USDT", fixed))
+
+		// Asks rest before the first trade gives the band a reference
+		for _, price := range []string{"50400", "50500", "50500.05"} {
+			_, err := me.PlaceOrder(newTestOrder(SideSell, OrderTypeLimit, "1", price))
+			require.NoError(t, err, price)
+		}
+		tradeAt(t, me, "50000")
+		return me
+	}
+	decimalEngine, fixedEngine := newEngine(false), newEngine(true)
+
+	tests := []struct {
+		name            string
+		quantity, price string
+		code            ErrorCode
+	}{
+		{"valid", "0.01", "49999.95", ""},
+		{"off tick", "0.01", "49999.92", ErrCodeInvalidTickSize},
+		{"finer than scale", "0.01", "49999.001", ErrCodeInvalidTickSize},
+		{"off step", "0.0105", "49999", ErrCodeInvalidStepSize},
+		{"below minimum", "0.009", "49999", ErrCodeQuantityTooSmall},
+		{"above maximum", "100.001", "49999", ErrCodeQuantityTooLarge},
+		{"below notional", "0.01", "900", ErrCodeNotionalTooSmall},
+		{"outside band", "0.01", "49400", ErrCodePriceOutOfBand},
+	}
+
+	for _, tt := range tests {
+		for _, me := range []*MatchingEngine{decimalEngine, fixedEngine} {
+			_, err := me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, tt.quantity, tt.price))
+			assert.Equal(t, tt.code, ErrorCodeOf(err), tt.name)
+		}
+	}
+
+	// Price protection (band [49500, 50500]) stops a sweep at the same level
+	for _, me := range []*MatchingEngine{decimalEngine, fixedEngine} {
+		market := newTestMarketOrder(SideBuy, "3")
+		trades, err := me.PlaceOrder(market)
+		require.NoError(t, err)
+		assert.Equal(t, 2, len(trades))
+		assert.Equal(t, StatusReasonPriceProtection, market.StatusReason)
+		assert.Equal(t, "2", market.FilledQuantity.String())
+	}
+}
+
+func TestMatchingEngine_FixedPoint_DecimalFieldsStayCurrent(t *testing.T) {
+	me := newTestFixedEngine(t, true)
+
+	ask := newTestOrder(SideSell, OrderTypeLimit, "1.0", "50000")
+	_, err := me.PlaceOrder(ask)
+	require.NoError(t, err)
+
+	bid := newTestOrder(SideBuy, OrderTypeLimit, "0.4", "50000")
+	trades, err := me.PlaceOrder(bid)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trades))
+
+	assert.Equal(t, "0.4", trades[0].Quantity.String())
+	assert.Equal(t, "0.4", ask.FilledQuantity.String())
+	assert.Equal(t, "0.6", ask.RemainingQuantity().String())
+	assert.Equal(t, OrderStatusPartiallyFilled, ask.Status)
+	assert.Equal(t, OrderStatusFilled, bid.Status)
+
+	ob, _ := me.GetOrderBook("BTC/USDT")
+	assert.Equal(t, "0.6", ob.Asks.Peek().Quantity.String())
+	assert.Equal(t, int64(6000), ob.Asks.Peek().fxQuantity)
+}
+
+func TestMatchingEngine_FixedPoint_SelfTradeDecrement(t *testing.T) {
+	me := newTestFixedEngine(t, true)
+
+	resting := newTestOrder(SideSell, OrderTypeLimit, "1.0", "50000")
+	resting.UserID = "user-1"
+	_, err := me.PlaceOrder(resting)
+	require.NoError(t, err)
+
+	incoming := newTestOrder(SideBuy, OrderTypeLimit, "0.25", "50000")
+	incoming.UserID = "user-1"
+	incoming.STPMode = STPModeDecrementAndCancel
+	_, err = me.PlaceOrder(incoming)
+	require.NoError(t, err)
+
+	// The resting order shrank in both representations and still matches
+	assert.Equal(t, int64(7500), resting.fxQty)
+	trades, err := me.PlaceOrder(newTestMarketOrder(SideBuy, "1.0"))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trades))
+	assert.Equal(t, "0.75", trades[0].Quantity.String())
+	assert.Equal(t, OrderStatusFilled, resting.Status)
+}
@@ -0,0 +1,612 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - WRITE-AHEAD JOURNAL
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Durability, NFR-005)
+// Description: Append-only journal of every inbound command (place, cancel,
+//              amend, admin status / halt). Each command is written on its
+//              book's goroutine before it executes; on startup the journal
+//              is replayed with the original command times to rebuild every
+//              order book.
+//
+// Format: one JSON entry per line, in segment files named after their first
+// entry. A torn last line (crash mid-write) is truncated when the journal is
+// opened.
+// ============================================================================
+
+package matching
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FsyncPolicy controls when journal writes are flushed to stable storage
+type FsyncPolicy string
+
+const (
+	FsyncAlways   FsyncPolicy = "always"   // fsync before each command executes
+	FsyncInterval FsyncPolicy = "interval" // fsync every FsyncInterval
+	FsyncNever    FsyncPolicy = "never"    // leave it to the OS (survives process crashes only)
+)
+
+// JournalConfig configures the write-ahead journal
+type JournalConfig struct {
+	Dir           string // Holds the journal-<first seq>.log segments
+	Fsync         FsyncPolicy
+	FsyncInterval time.Duration
+}
+
+// JournalEntryType identifies the journaled command
+type JournalEntryType string
+
+const (
+	JournalPlace        JournalEntryType = "PLACE"
+	JournalCancel       JournalEntryType = "CANCEL"
+	JournalCancelAll    JournalEntryType = "CANCEL_ALL"
+	JournalAmend        JournalEntryType = "AMEND"
+	JournalSymbolStatus JournalEntryType = "SYMBOL_STATUS"
+	JournalHalt         JournalEntryType = "HALT"
+	JournalResume       JournalEntryType = "RESUME"
+)
+
+// JournalEntry is one journaled command
+type JournalEntry struct {
+	Seq    uint64           `json:"seq"`
+	Type   JournalEntryType `json:"type"`
+	Time   time.Time        `json:"time"` // Command time, reused on replay
+	Symbol string           `json:"symbol"`
+
+	Order    *Order          `json:"order,omitempty"`    // PLACE: the order as submitted
+	OrderID  string          `json:"order_id,omitempty"` // CANCEL, AMEND
+	UserID   string          `json:"user_id,omitempty"`  // CANCEL, CANCEL_ALL, AMEND: requesting user
+	Side     Side            `json:"side,omitempty"`     // CANCEL_ALL ("" = both sides)
+	Price    decimal.Decimal `json:"price"`              // AMEND (zero keeps the price)
+	Quantity decimal.Decimal `json:"quantity"`           // AMEND (zero keeps the quantity)
+
+	Status   SymbolStatus  `json:"status,omitempty"`   // SYMBOL_STATUS
+	Duration time.Duration `json:"duration,omitempty"` // HALT
+	Reason   string        `json:"reason,omitempty"`   // SYMBOL_STATUS, HALT, RESUME
+}
+
+// Journal is an append-only command log, safe for concurrent appends.
+// It is split into segments at each snapshot (Rotate) so segments fully
+// covered by a snapshot can be deleted (Compact).
+type Journal struct {
+	cfg      JournalConfig
+	mu       sync.Mutex
+	segments []uint64 // First seq of each segment, oldest first; the last is active
+	file     *os.File // Active segment
+	seq      uint64   // Seq of the last entry
+	size     int64    // Valid bytes of the active segment; replay reads up to here
+
+	dirty  bool // Written since the last fsync (FsyncInterval)
+	done   chan struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// OpenJournal opens (or creates) the journal in cfg.Dir, dropping a torn
+// last entry left by a crash
+func OpenJournal(cfg JournalConfig) (*Journal, error) {
+	switch cfg.Fsync {
+	case FsyncAlways, FsyncNever:
+	case FsyncInterval:
+		if cfg.FsyncInterval <= 0 {
+			return nil, errors.New("journal fsync interval must be positive")
+		}
+	case "":
+		cfg.Fsync = FsyncAlways
+	default:
+		return nil, fmt.Errorf("invalid journal fsync policy: %s", cfg.Fsync)
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create journal directory: %w", err)
+	}
+	segments, err := listSequenced(cfg.Dir, "journal-", ".log")
+	if err != nil {
+		return nil, fmt.Errorf("list journal segments: %w", err)
+	}
+	if len(segments) == 0 {
+		segments = []uint64{1}
+	}
+
+	j := &Journal{cfg: cfg, segments: segments, seq: segments[0] - 1, done: make(chan struct{})}
+	if err := j.recover(); err != nil {
+		if j.file != nil {
+			j.file.Close()
+		}
+		return nil, err
+	}
+
+	if cfg.Fsync == FsyncInterval {
+		j.wg.Add(1)
+		go j.syncLoop()
+	}
+	return j, nil
+}
+
+func (j *Journal) segmentPath(first uint64) string {
+	return filepath.Join(j.cfg.Dir, sequencedName("journal-", first, ".log"))
+}
+
+// recover checks the sealed segments, then finds the last complete entry of
+// the active segment and truncates anything after it
+func (j *Journal) recover() error {
+	sealed := j.segments[:len(j.segments)-1]
+	for _, first := range sealed {
+		if err := j.checkSegment(first); err != nil {
+			return err
+		}
+	}
+
+	active := j.segments[len(j.segments)-1]
+	if active != j.seq+1 {
+		return fmt.Errorf("journal segment %d follows entry %d", active, j.seq)
+	}
+
+	file, err := os.OpenFile(j.segmentPath(active), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("open journal: %w", err)
+	}
+	j.file = file
+
+	offset, err := j.scanSegment(file)
+	if err != nil {
+		return err
+	}
+
+	if err := file.Truncate(offset); err != nil {
+		return fmt.Errorf("truncate journal: %w", err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	j.size = offset
+	return nil
+}
+
+// checkSegment verifies a sealed segment continues the journal
+func (j *Journal) checkSegment(first uint64) error {
+	if first != j.seq+1 {
+		return fmt.Errorf("journal segment %d follows entry %d", first, j.seq)
+	}
+
+	file, err := os.Open(j.segmentPath(first))
+	if err != nil {
+		return fmt.Errorf("open journal segment: %w", err)
+	}
+	defer file.Close()
+
+	_, err = j.scanSegment(file)
+	return err
+}
+
+// scanSegment advances seq over the entries of r and returns the offset just
+// past the last complete one
+func (j *Journal) scanSegment(r io.Reader) (int64, error) {
+	var offset int64
+	err := scanJournal(r, func(entry *JournalEntry, end int64) error {
+		if entry.Seq != j.seq+1 {
+			return fmt.Errorf("journal entry %d follows %d", entry.Seq, j.seq)
+		}
+		j.seq, offset = entry.Seq, end
+		return nil
+	})
+	return offset, err
+}
+
+// Append assigns the entry's Seq and writes it, syncing per the fsync policy
+func (j *Journal) Append(entry *JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return errors.New("journal is closed")
+	}
+
+	entry.Seq = j.seq + 1
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode journal entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	if _, err := j.file.Write(data); err != nil {
+		j.rollback()
+		return fmt.Errorf("write journal: %w", err)
+	}
+
+	switch j.cfg.Fsync {
+	case FsyncAlways:
+		if err := j.file.Sync(); err != nil {
+			j.rollback()
+			return fmt.Errorf("sync journal: %w", err)
+		}
+	case FsyncInterval:
+		j.dirty = true
+	}
+
+	j.seq = entry.Seq
+	j.size += int64(len(data))
+	return nil
+}
+
+// rollback drops a failed entry so the command is not replayed and the next
+// entry starts on a clean line
+func (j *Journal) rollback() {
+	j.file.Truncate(j.size)
+	j.file.Seek(j.size, io.SeekStart)
+}
+
+// Seq returns the sequence number of the last entry
+func (j *Journal) Seq() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.seq
+}
+
+// Start returns the sequence number before the oldest retained entry: the
+// journal can only be replayed on top of state at or after it
+func (j *Journal) Start() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.segments[0] - 1
+}
+
+// Rotate seals the active segment and starts a new one with the next entry.
+// A no-op while the active segment is empty.
+func (j *Journal) Rotate() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return errors.New("journal is closed")
+	}
+	if j.size == 0 {
+		return nil
+	}
+
+	// Sealed segments are always durable, whatever the fsync policy
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("sync journal: %w", err)
+	}
+
+	first := j.seq + 1
+	file, err := os.OpenFile(j.segmentPath(first), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("create journal segment: %w", err)
+	}
+	if err := syncDir(j.cfg.Dir); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	j.file.Close()
+	j.file, j.size, j.dirty = file, 0, false
+	j.segments = append(j.segments, first)
+	return nil
+}
+
+// Compact deletes the sealed segments holding only entries up to seq and
+// returns how many were deleted
+func (j *Journal) Compact(seq uint64) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	removed := 0
+	for removed < len(j.segments)-1 && j.segments[removed+1]-1 <= seq {
+		if err := os.Remove(j.segmentPath(j.segments[removed])); err != nil && !errors.Is(err, os.ErrNotExist) {
+			j.segments = j.segments[removed:]
+			return removed, fmt.Errorf("delete journal segment: %w", err)
+		}
+		removed++
+	}
+
+	j.segments = j.segments[removed:]
+	return removed, nil
+}
+
+// Sync flushes written entries to stable storage
+func (j *Journal) Sync() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.sync()
+}
+
+func (j *Journal) sync() error {
+	if j.closed || !j.dirty {
+		return nil
+	}
+	j.dirty = false
+	return j.file.Sync()
+}
+
+func (j *Journal) syncLoop() {
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(j.cfg.FsyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.Sync()
+		case <-j.done:
+			return
+		}
+	}
+}
+
+// Close syncs and closes the journal
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	if j.closed {
+		j.mu.Unlock()
+		return nil
+	}
+	close(j.done)
+	j.dirty = true
+	err := j.sync()
+	j.closed = true
+	if closeErr := j.file.Close(); err == nil {
+		err = closeErr
+	}
+	j.mu.Unlock()
+
+	j.wg.Wait()
+	return err
+}
+
+// Entries calls fn for every entry after seq, in order
+func (j *Journal) Entries(after uint64, fn func(entry *JournalEntry) error) error {
+	j.mu.Lock()
+	segments := append([]uint64(nil), j.segments...)
+	active, size := j.file, j.size
+	j.mu.Unlock()
+
+	for i, first := range segments {
+		last := i == len(segments)-1
+		if !last && segments[i+1]-1 <= after {
+			continue // Entirely at or before after
+		}
+
+		var err error
+		if last {
+			err = entriesAfter(io.NewSectionReader(active, 0, size), after, fn)
+		} else {
+			err = ReadJournalFile(j.segmentPath(first), func(entry *JournalEntry) error {
+				if entry.Seq <= after {
+					return nil
+				}
+				return fn(entry)
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadJournal calls fn for every entry of the journal in dir, in order,
+// without opening it for writing (safe while an engine appends to it)
+func ReadJournal(dir string, fn func(entry *JournalEntry) error) error {
+	segments, err := listSequenced(dir, "journal-", ".log")
+	if err != nil {
+		return fmt.Errorf("list journal segments: %w", err)
+	}
+
+	for _, first := range segments {
+		if err := ReadJournalFile(filepath.Join(dir, sequencedName("journal-", first, ".log")), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadJournalFile calls fn for every entry of one journal segment, or of a
+// recorded input file in the same one-entry-per-line format
+func ReadJournalFile(path string, fn func(entry *JournalEntry) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return scanJournal(file, func(entry *JournalEntry, _ int64) error {
+		return fn(entry)
+	})
+}
+
+func entriesAfter(r io.Reader, after uint64, fn func(entry *JournalEntry) error) error {
+	return scanJournal(r, func(entry *JournalEntry, _ int64) error {
+		if entry.Seq <= after {
+			return nil
+		}
+		return fn(entry)
+	})
+}
+
+// scanJournal decodes entries from r, passing each with the offset just past
+// it. A torn or undecodable last line ends the scan; damage before the last
+// line is an error.
+func scanJournal(r io.Reader, fn func(entry *JournalEntry, end int64) error) error {
+	reader := bufio.NewReader(r)
+	var offset int64
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil // Empty or torn (unterminated) last line
+		}
+		if err != nil {
+			return fmt.Errorf("read journal: %w", err)
+		}
+
+		var entry JournalEntry
+		if decodeErr := json.Unmarshal(bytes.TrimSpace(line), &entry); decodeErr != nil {
+			if _, peekErr := reader.Peek(1); peekErr == io.EOF {
+				return nil // Garbled last line
+			}
+			return fmt.Errorf("journal corrupted at offset %d: %w", offset, decodeErr)
+		}
+
+		offset += int64(len(line))
+		if err := fn(&entry, offset); err != nil {
+			return err
+		}
+	}
+}
+
+// sequencedName names a journal segment or snapshot file after a sequence
+// number, zero-padded so names sort in sequence order
+func sequencedName(prefix string, seq uint64, suffix string) string {
+	return fmt.Sprintf("%s%020d%s", prefix, seq, suffix)
+}
+
+// listSequenced returns the sequence numbers of the files of dir named by
+// sequencedName, ascending
+func listSequenced(dir, prefix, suffix string) ([]uint64, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	seqs := make([]uint64, 0, len(files))
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix), 10, 64)
+		if err != nil {
+			continue // Not ours (e.g. a temp file)
+		}
+		seqs = append(seqs, seq)
+	}
+
+	sort.Slice(seqs, func(a, b int) bool { return seqs[a] < seqs[b] })
+	return seqs, nil
+}
+
+// syncDir makes file creations, renames and deletions in dir durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %w", dir, err)
+	}
+	return nil
+}
+
+// ============================================================================
+// ENGINE INTEGRATION
+// ============================================================================
+
+// record journals a command before it executes, stamped with the command
+// time. A no-op without a journal (and while replaying). Runs on the book's
+// goroutine.
+func (me *MatchingEngine) record(ob *OrderBook, entry *JournalEntry) error {
+	if me.journal == nil {
+		return nil
+	}
+
+	entry.Time = ob.now
+	return me.journal.Append(entry)
+}
+
+// RecoverFromJournal replays the journaled commands not covered by a
+// restored snapshot (all of them without one) against the engine, with
+// their original command times, and then journals new commands to j.
+// Symbol specs and breaker settings must be configured first, and event
+// subscribers are best attached afterwards: replayed commands publish too.
+func (me *MatchingEngine) RecoverFromJournal(j *Journal) (int, error) {
+	if me.journal != nil {
+		return 0, errors.New("journal already attached")
+	}
+	if start := j.Start(); me.restoredSeq < start {
+		return 0, fmt.Errorf("journal starts after entry %d but the engine state is at entry %d", start, me.restoredSeq)
+	}
+	if end := j.Seq(); end < me.restoredSeq {
+		return 0, fmt.Errorf("journal ends at entry %d but the engine state is at entry %d", end, me.restoredSeq)
+	}
+
+	replayed := 0
+	err := j.Entries(me.restoredSeq, func(entry *JournalEntry) error {
+		if err := me.replay(entry); err != nil {
+			return fmt.Errorf("replay journal entry %d: %w", entry.Seq, err)
+		}
+		replayed++
+		return nil
+	})
+	if err != nil {
+		return replayed, err
+	}
+
+	me.journal = j
+	return replayed, nil
+}
+
+// ReplayEntry executes a journaled (or recorded) command on an engine
+// without a journal, at the entry's time or, if it has none, the engine
+// clock's. A rejected command is not an error: it is part of the history.
+func (me *MatchingEngine) ReplayEntry(entry *JournalEntry) error {
+	if me.journal != nil {
+		return errors.New("cannot replay into an engine with a journal attached")
+	}
+	return me.replay(entry)
+}
+
+// replay re-executes one command. Command errors (rejections) are part of
+// the original history and are expected to recur.
+func (me *MatchingEngine) replay(entry *JournalEntry) error {
+	at := entry.Time
+	if at.IsZero() {
+		at = me.clock.Now()
+	}
+
+	switch entry.Type {
+	case JournalPlace:
+		if entry.Order == nil {
+			return errors.New("place entry without order")
+		}
+		me.submitOrder(entry.Order, at).Wait()
+	case JournalCancel:
+		me.submitCancel(entry.OrderID, entry.Symbol, entry.UserID, at).Wait()
+	case JournalCancelAll:
+		if ob, ok := me.GetOrderBook(entry.Symbol); ok {
+			me.cancelUserOrdersAt(ob, entry.UserID, entry.Side, at)
+		}
+	case JournalAmend:
+		me.amendOrderAt(entry.OrderID, entry.Symbol, entry.UserID, entry.Price, entry.Quantity, at)
+	case JournalSymbolStatus:
+		me.setSymbolStatusAt(entry.Symbol, entry.Status, entry.Reason, at)
+	case JournalHalt:
+		me.triggerCircuitBreakerAt(entry.Symbol, entry.Duration, entry.Reason, at)
+	case JournalResume:
+		me.resetCircuitBreakerAt(entry.Symbol, entry.Reason, at)
+	default:
+		return fmt.Errorf("unknown journal entry type: %s", entry.Type)
+	}
+	return nil
+}
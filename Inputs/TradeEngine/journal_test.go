@@ -0,0 +1,310 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - WRITE-AHEAD JOURNAL TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestJournal(t *testing.T, dir string) *Journal {
+	j, err := OpenJournal(JournalConfig{Dir: dir, Fsync: FsyncNever})
+	require.NoError(t, err)
+	return j
+}
+
+func journalEntries(t *testing.T, j *Journal) []*JournalEntry {
+	entries := make([]*JournalEntry, 0)
+	require.NoError(t, j.Entries(0, func(entry *JournalEntry) error {
+		entries = append(entries, entry)
+		return nil
+	}))
+	return entries
+}
+
+// orderState is the replay-relevant state of an order
+type orderState struct {
+	Status    OrderStatus
+	Price     string
+	Quantity  string
+	Filled    string
+	UpdatedAt time.Time
+	Sequence  uint64
+}
+
+func bookState(t *testing.T, me *MatchingEngine, symbol string) (map[string]interface{}, map[string]orderState) {
+	orders := make(map[string]orderState)
+	require.True(t, me.ReadOrderBook(symbol, func(ob *OrderBook) {
+		for id, order := range ob.Orders {
+			orders[id] = orderState{
+				Status:    order.Status,
+				Price:     order.Price.String(),
+				Quantity:  order.Quantity.String(),
+				Filled:    order.FilledQuantity.String(),
+				UpdatedAt: order.UpdatedAt.UTC(),
+				Sequence:  order.Sequence,
+			}
+		}
+	}))
+	return me.GetOrderBookSnapshot(symbol, 10), orders
+}
+
+func TestOpenJournal_InvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := OpenJournal(JournalConfig{Dir: dir, Fsync: "sometimes"})
+	assert.Error(t, err)
+
+	_, err = OpenJournal(JournalConfig{Dir: dir, Fsync: FsyncInterval})
+	assert.Error(t, err)
+
+	// Not a directory
+	file := filepath.Join(dir, "journal.log")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+	_, err = OpenJournal(JournalConfig{Dir: file})
+	assert.Error(t, err)
+}
+
+func TestJournal_AppendAndReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, policy := range []FsyncPolicy{FsyncAlways, FsyncInterval, FsyncNever} {
+		j, err := OpenJournal(JournalConfig{Dir: dir, Fsync: policy, FsyncInterval: time.Millisecond})
+		require.NoError(t, err)
+
+		entry := &JournalEntry{Type: JournalCancel, Symbol: "BTC/USDT", OrderID: string(policy)}
+		require.NoError(t, j.Append(entry))
+		require.NoError(t, j.Close())
+	}
+
+	// Sequence numbers continue across reopens
+	j := openTestJournal(t, dir)
+	defer j.Close()
+
+	entries := journalEntries(t, j)
+	require.Equal(t, 3, len(entries))
+	for i, entry := range entries {
+		assert.Equal(t, uint64(i+1), entry.Seq)
+	}
+	assert.Equal(t, "interval", entries[1].OrderID)
+	assert.Equal(t, uint64(3), j.Seq())
+}
+
+func TestJournal_TornTail(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, sequencedName("journal-", 1, ".log"))
+
+	j := openTestJournal(t, dir)
+	require.NoError(t, j.Append(&JournalEntry{Type: JournalCancel, Symbol: "BTC/USDT", OrderID: "order-1"}))
+	require.NoError(t, j.Close())
+
+	// Crash mid-write: half an entry without its newline
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"seq":2,"type":"CANC`)
+	require.NoError(t, err)
+	file.Close()
+
+	j = openTestJournal(t, dir)
+	assert.Equal(t, uint64(1), j.Seq())
+
+	// The next entry replaces the torn one
+	require.NoError(t, j.Append(&JournalEntry{Type: JournalCancel, Symbol: "BTC/USDT", OrderID: "order-2"}))
+	entries := journalEntries(t, j)
+	require.Equal(t, 2, len(entries))
+	assert.Equal(t, "order-2", entries[1].OrderID)
+	require.NoError(t, j.Close())
+
+	j = openTestJournal(t, dir)
+	assert.Equal(t, uint64(2), j.Seq())
+	j.Close()
+}
+
+func TestJournal_CorruptedEntry(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, sequencedName("journal-", 1, ".log"))
+	data := `{"seq":1,"type":"CANCEL","symbol":"BTC/USDT"}` + "\n" +
+		`garbage` + "\n" +
+		`{"seq":2,"type":"CANCEL","symbol":"BTC/USDT"}` + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	// Damage before the last entry is not a torn write
+	_, err := OpenJournal(JournalConfig{Dir: dir})
+	assert.Error(t, err)
+}
+
+func TestMatchingEngine_RecoverFromJournal(t *testing.T) {
+	dir := t.TempDir()
+	breaker, err := NewCircuitBreakerConfig("10", time.Minute, 5*time.Minute)
+	require.NoError(t, err)
+
+	newEngine := func() *MatchingEngine {
+		me := newTestEngine()
+		me.SetCircuitBreaker("BTC/USDT", breaker)
+		return me
+	}
+
+	// Original run
+	me := newEngine()
+	j := openTestJournal(t, dir)
+	replayed, err := me.RecoverFromJournal(j)
+	require.NoError(t, err)
+	assert.Equal(t, 0, replayed)
+	events := recordEvents(t, me)
+
+	ask := newTestOrder(SideSell, OrderTypeLimit, "1.0", "50000")
+	bid := newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49900")
+	farAsk := newTestOrder(SideSell, OrderTypeLimit, "2.0", "50100")
+	for _, order := range []*Order{ask, bid, farAsk} {
+		_, err := me.PlaceOrder(order)
+		require.NoError(t, err)
+	}
+
+	trades, err := me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "0.4", "50000"))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trades))
+
+	_, err = me.AmendOrder(bid.OrderID, "BTC/USDT", bid.UserID, decimal.RequireFromString("49950"), decimal.RequireFromString("1.5"))
+	require.NoError(t, err)
+	require.NoError(t, me.CancelOrder(farAsk.OrderID, "BTC/USDT", farAsk.UserID))
+
+	carol := userOrder("carol", "BTC/USDT", SideBuy, "1.0", "49800")
+	_, err = me.PlaceOrder(carol)
+	require.NoError(t, err)
+	cancelled, err := me.CancelAllOrders("carol", "BTC/USDT", "")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(cancelled))
+
+	// Rejected commands are journaled too and rejected again on replay
+	fok := newTestOrder(SideBuy, OrderTypeLimit, "5.0", "50000")
+	fok.TimeInForce = TimeInForceFOK
+	_, err = me.PlaceOrder(fok)
+	assert.Error(t, err)
+
+	require.NoError(t, me.TriggerCircuitBreaker("BTC/USDT", 5*time.Minute, "maintenance"))
+	_, err = me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49000"))
+	assert.Error(t, err)
+
+	wantSnapshot, wantOrders := bookState(t, me, "BTC/USDT")
+	wantGlobal := me.GlobalSequence()
+	wantTrades := events.trades()
+	me.Close()
+	require.NoError(t, j.Close())
+
+	// Restart: replay rebuilds the same book, halt, sequence numbers and
+	// trades, IDs included
+	recovered := newEngine()
+	recoveredEvents := recordEvents(t, recovered)
+	j = openTestJournal(t, dir)
+	defer j.Close()
+
+	replayed, err = recovered.RecoverFromJournal(j)
+	require.NoError(t, err)
+	assert.Equal(t, 11, replayed)
+	gotTrades := recoveredEvents.trades()
+	require.Equal(t, 1, len(wantTrades))
+	require.Equal(t, len(wantTrades), len(gotTrades))
+	for i, want := range wantTrades {
+		got := gotTrades[i]
+		assert.Equal(t, want.TradeID, got.TradeID)
+		assert.Equal(t, want.BuyerOrderID, got.BuyerOrderID)
+		assert.Equal(t, want.SellerOrderID, got.SellerOrderID)
+		assert.True(t, want.Price.Equal(got.Price))
+		assert.True(t, want.Quantity.Equal(got.Quantity))
+		assert.True(t, want.ExecutedAt.Equal(got.ExecutedAt))
+	}
+
+	gotSnapshot, gotOrders := bookState(t, recovered, "BTC/USDT")
+	assert.Equal(t, wantSnapshot, gotSnapshot)
+	assert.Equal(t, wantOrders, gotOrders)
+	assert.Equal(t, wantGlobal, recovered.GlobalSequence())
+	assert.True(t, recovered.IsHalted("BTC/USDT"))
+
+	// New commands are appended after the replayed ones
+	require.NoError(t, recovered.ResetCircuitBreaker("BTC/USDT", "resumed"))
+	assert.Equal(t, uint64(12), j.Seq())
+	assert.False(t, recovered.IsHalted("BTC/USDT"))
+
+	_, err = recovered.RecoverFromJournal(j)
+	assert.Error(t, err)
+}
+
+func TestReadJournal_ReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, sequencedName("journal-", 1, ".log"))
+
+	j := openTestJournal(t, dir)
+	require.NoError(t, j.Append(&JournalEntry{Type: JournalCancel, Symbol: "BTC/USDT", OrderID: "order-1"}))
+	require.NoError(t, j.Close())
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"seq":2,"type":"CANC`)
+	require.NoError(t, err)
+	file.Close()
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// The torn tail is skipped but left in place
+	entries := make([]*JournalEntry, 0)
+	require.NoError(t, ReadJournal(dir, func(entry *JournalEntry) error {
+		entries = append(entries, entry)
+		return nil
+	}))
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, "order-1", entries[0].OrderID)
+
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+}
+
+func TestMatchingEngine_ReplayEntry_Deterministic(t *testing.T) {
+	ask := newTestOrder(SideSell, OrderTypeLimit, "1.0", "50000")
+	bid := newTestOrder(SideBuy, OrderTypeLimit, "1.5", "50000")
+	entries := []*JournalEntry{
+		{Type: JournalPlace, Symbol: "BTC/USDT", Order: ask},
+		{Type: JournalPlace, Symbol: "BTC/USDT", Order: bid},
+		{Type: JournalCancel, Symbol: "BTC/USDT", OrderID: bid.OrderID},
+	}
+
+	// Recorded input without times: the same clock gives the same trade IDs
+	// and timestamps on every replay
+	replay := func() []*Trade {
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		me := newTestEngine(WithClock(NewStepClock(start, time.Millisecond)))
+		defer me.Close()
+
+		events := recordEvents(t, me)
+		for _, entry := range entries {
+			copied := *entry
+			if entry.Order != nil {
+				order := *entry.Order
+				copied.Order = &order
+			}
+			require.NoError(t, me.ReplayEntry(&copied))
+		}
+		return events.trades()
+	}
+
+	first, second := replay(), replay()
+	require.Equal(t, 1, len(first))
+	assert.Equal(t, first, second)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, int(time.Millisecond), time.UTC), first[0].ExecutedAt)
+
+	// A live engine journals instead
+	me := newTestEngine()
+	j := openTestJournal(t, t.TempDir())
+	defer j.Close()
+	_, err := me.RecoverFromJournal(j)
+	require.NoError(t, err)
+	assert.Error(t, me.ReplayEntry(entries[0]))
+}
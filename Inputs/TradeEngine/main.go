@@ -31,39 +31,117 @@ const (
 )
 
 func main() {
-	log.Printf("Starting %s v%s...", ServiceName, Version)
-
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		if err := runReplay(cfg, os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("replay: %v", err)
+		}
+		return
+	}
+
+	log.Printf("Starting %s v%s...", ServiceName, Version)
+
 	// Initialize matching engine
 	engine := matching.NewMatchingEngine()
+	engine.CommandQueueSize = cfg.Trading.Matching.CommandQueueSize
 	
-	// Setup callbacks
-	engine.OnTrade = func(trade *matching.Trade) {
-		log.Printf("TRADE: %s @ %s qty=%s", 
-			trade.Symbol, trade.Price, trade.Quantity)
-		// TODO: Publish to Kafka
+	if err := setupSymbols(engine, cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	// Rebuild the order books: latest snapshot, then the journal tail (NFR-005)
+	sc := cfg.Persistence.Snapshots
+	if sc.Enabled {
+		snapshot, err := matching.LatestSnapshot(sc.Dir)
+		if err != nil {
+			log.Fatalf("Failed to read snapshot: %v", err)
+		}
+		if snapshot != nil {
+			if err := engine.RestoreSnapshot(snapshot); err != nil {
+				log.Fatalf("Failed to restore snapshot: %v", err)
+			}
+			log.Printf("Restored snapshot at journal entry %d (taken %s)",
+				snapshot.JournalSeq, snapshot.TakenAt.Format(time.RFC3339))
+		}
 	}
 	
-	engine.OnOrderUpdate = func(order *matching.Order) {
-		log.Printf("ORDER UPDATE: %s status=%s", 
-			order.OrderID, order.Status)
-		// TODO: Publish to Kafka
+	// Order and trade history for the query API (FR-004), including what
+	// the journal replay publishes
+	orders, err := matching.NewOrderStore(engine)
+	if err != nil {
+		log.Fatalf("Failed to create order store: %v", err)
+	}
+	trades, err := matching.NewTradeStore(engine, cfg.Trading.Matching.RecentTrades)
+	if err != nil {
+		log.Fatalf("Invalid trading.matching.recent_trades: %v", err)
+	}
+	
+	var journal *matching.Journal
+	if jc := cfg.Persistence.Journal; jc.Enabled {
+		journal, err = matching.OpenJournal(matching.JournalConfig{
+			Dir:           jc.Dir,
+			Fsync:         matching.FsyncPolicy(jc.Fsync),
+			FsyncInterval: jc.FsyncInterval,
+		})
+		if err != nil {
+			log.Fatalf("Failed to open journal: %v", err)
+		}
+		
+		replayed, err := engine.RecoverFromJournal(journal)
+		if err != nil {
+			log.Fatalf("Failed to replay journal: %v", err)
+		}
+		log.Printf("Replayed %d journal entries from %s", replayed, jc.Dir)
 	}
 
-	// Create symbols (temporary - will come from DB)
-	symbols := []string{"BTC/USDT", "ETH/USDT", "BNB/USDT"}
-	for _, symbol := range symbols {
-		engine.GetOrCreateOrderBook(symbol)
-		log.Printf("Initialized order book: %s", symbol)
+	// Subscribe the event publisher (after recovery: replayed events were
+	// already published)
+	publisher, err := engine.Subscribe(matching.EventSinkFunc(publishEvent), matching.SubscriptionConfig{
+		BufferSize:   cfg.Kafka.BufferSize,
+		Backpressure: matching.BackpressurePolicy(cfg.Kafka.Backpressure),
+	})
+	if err != nil {
+		log.Fatalf("Invalid kafka event configuration: %v", err)
+	}
+	go func() {
+		<-publisher.Done()
+		if err := publisher.Err(); err != nil {
+			log.Printf("Event publisher stopped: %v", err)
+		}
+	}()
+
+	// Market data streams, seeded with the recovered order books
+	var stream *marketStream
+	if cfg.Features.WebSocket {
+		if stream, err = newMarketStream(engine); err != nil {
+			log.Fatalf("Failed to start market data streams: %v", err)
+		}
+	}
+
+	var snapshotter *matching.Snapshotter
+	if sc.Enabled {
+		snapshotter, err = matching.NewSnapshotter(engine, matching.SnapshotConfig{
+			Dir:      sc.Dir,
+			Interval: sc.Interval,
+			Retain:   sc.Retain,
+		})
+		if err != nil {
+			log.Fatalf("Invalid persistence.snapshots configuration: %v", err)
+		}
+		snapshotter.OnError = func(err error) {
+			log.Printf("Snapshot failed: %v", err)
+		}
+		snapshotter.Start()
 	}
 
 	// Setup HTTP server
-	router := setupRouter(engine, cfg)
+	router := setupRouter(engine, orders, trades, stream, cfg)
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -73,6 +151,15 @@ func main() {
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
+	// Resume symbols whose circuit breaker cooldown has expired
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			engine.CheckCircuitBreakers()
+		}
+	}()
+
 	// Start server in goroutine
 	go func() {
 		log.Printf("Server listening on %s", server.Addr)
@@ -96,16 +183,103 @@ func main() {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
 
+	// Final snapshot, so the next start has no journal to replay
+	if snapshotter != nil {
+		snapshotter.Stop()
+		if seq, err := snapshotter.Save(); err != nil {
+			log.Printf("Final snapshot failed: %v", err)
+		} else {
+			log.Printf("Snapshot written at journal entry %d", seq)
+		}
+	}
+
+	if stream != nil {
+		stream.close()
+	}
+
+	// Let every order book finish its queued commands and the publisher
+	// drain its events
+	engine.Close()
+	if journal != nil {
+		if err := journal.Close(); err != nil {
+			log.Printf("Failed to close journal: %v", err)
+		}
+	}
+
 	log.Println("Server exited")
 }
 
-func setupRouter(engine *matching.MatchingEngine, cfg *config.Config) *gin.Engine {
+// setupSymbols creates the order books with the configured trading rules
+func setupSymbols(engine *matching.MatchingEngine, cfg *config.Config) error {
+	// Default trading rules (temporary - per-symbol values will come from DB)
+	m := cfg.Trading.Matching
+	spec, err := matching.NewSymbolSpec(m.TickSize, m.StepSize, m.MinOrderSize, m.MaxOrderSize,
+		m.MinOrderValue, m.PriceBandPercentage)
+	if err != nil {
+		return fmt.Errorf("invalid trading.matching configuration: %w", err)
+	}
+
+	var breaker matching.CircuitBreakerConfig
+	if cb := cfg.Trading.CircuitBreaker; cb.Enabled {
+		breaker, err = matching.NewCircuitBreakerConfig(cb.ThresholdPercentage, cb.Window, cb.Cooldown)
+		if err != nil {
+			return fmt.Errorf("invalid trading.circuit_breaker configuration: %w", err)
+		}
+	}
+
+	// Create symbols (temporary - will come from DB)
+	symbols := []string{"BTC/USDT", "ETH/USDT", "BNB/USDT"}
+	for _, symbol := range symbols {
+		engine.GetOrCreateOrderBook(symbol)
+		if err := engine.SetSymbolSpec(symbol, spec); err != nil {
+			return err
+		}
+		if err := engine.SetCircuitBreaker(symbol, breaker); err != nil {
+			return err
+		}
+		if m.FixedPoint {
+			if err := engine.SetFixedPoint(symbol, true); err != nil {
+				return fmt.Errorf("cannot enable fixed-point matching for %s: %w", symbol, err)
+			}
+		}
+		log.Printf("Initialized order book: %s", symbol)
+	}
+	return nil
+}
+
+// publishEvent forwards an engine event. Runs on the publisher's
+// subscription goroutine, outside the matching loop.
+func publishEvent(event matching.Event) {
+	switch e := event.(type) {
+	case *matching.TradeEvent:
+		log.Printf("TRADE: %s #%d @ %s qty=%s", 
+			e.Symbol, e.Sequence, e.Trade.Price, e.Trade.Quantity)
+		// TODO: Publish to Kafka
+		
+	case *matching.CircuitBreakerEvent:
+		log.Printf("CIRCUIT BREAKER: %s %s reason=%s", 
+			e.Symbol, e.Action, e.Reason)
+		// TODO: Publish to Kafka + admin alert
+		
+	default:
+		if order := matching.OrderOf(event); order != nil {
+			log.Printf("ORDER UPDATE: %s #%d %s status=%s", 
+				order.Symbol, order.Sequence, order.OrderID, order.Status)
+			// TODO: Publish to Kafka
+		}
+	}
+}
+
+func setupRouter(engine *matching.MatchingEngine, orders *matching.OrderStore, trades *matching.TradeStore, stream *marketStream, cfg *config.Config) *gin.Engine {
 	if cfg.Server.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	router := gin.Default()
 
+	// Symbols contain a slash: path parameters arrive escaped (BTC%2FUSDT)
+	router.UseRawPath = true
+
 	// Health check
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -122,15 +296,24 @@ func setupRouter(engine *matching.MatchingEngine, cfg *config.Config) *gin.Engin
 		// Market data
 		v1.GET("/market-data/ticker/:symbol", func(c *gin.Context) {
 			symbol := c.Param("symbol")
-			ob := engine.GetOrCreateOrderBook(symbol)
 			
-			c.JSON(http.StatusOK, gin.H{
-				"symbol":     symbol,
-				"last_price": ob.LastPrice.String(),
-				"best_bid":   ob.GetBestBid().String(),
-				"best_ask":   ob.GetBestAsk().String(),
-				"timestamp":  time.Now().Format(time.RFC3339),
+			var ticker gin.H
+			ok := engine.ReadOrderBook(symbol, func(ob *matching.OrderBook) {
+				ticker = gin.H{
+					"symbol":     symbol,
+					"status":     ob.Status,
+					"last_price": ob.LastPrice.String(),
+					"best_bid":   ob.GetBestBid().String(),
+					"best_ask":   ob.GetBestAsk().String(),
+					"timestamp":  time.Now().Format(time.RFC3339),
+				}
 			})
+			if !ok {
+				c.JSON(http.StatusNotFound, gin.H{"error": "unknown symbol: " + symbol})
+				return
+			}
+			
+			c.JSON(http.StatusOK, ticker)
 		})
 
 		v1.GET("/market-data/orderbook/:symbol", func(c *gin.Context) {
@@ -138,6 +321,10 @@ func setupRouter(engine *matching.MatchingEngine, cfg *config.Config) *gin.Engin
 			depth := 20 // Default depth
 			
 			snapshot := engine.GetOrderBookSnapshot(symbol, depth)
+			if snapshot == nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "unknown symbol: " + symbol})
+				return
+			}
 			c.JSON(http.StatusOK, snapshot)
 		})
 
@@ -147,28 +334,20 @@ func setupRouter(engine *matching.MatchingEngine, cfg *config.Config) *gin.Engin
 			c.JSON(http.StatusOK, stats)
 		})
 
-		// Demo order placement (MVP - no auth for testing)
-		v1.POST("/demo/orders", func(c *gin.Context) {
-			var req struct {
-				Symbol   string `json:"symbol" binding:"required"`
-				Side     string `json:"side" binding:"required"`
-				Type     string `json:"order_type" binding:"required"`
-				Quantity string `json:"quantity" binding:"required"`
-				Price    string `json:"price"`
-			}
+		// Orders
+		registerOrderRoutes(v1, engine, orders)
 
-			if err := c.ShouldBindJSON(&req); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
+		// Trades
+		registerTradeRoutes(v1, engine, trades)
+	}
 
-			// TODO: Create order and place it
-			c.JSON(http.StatusCreated, gin.H{
-				"message": "Order placement endpoint - full implementation in Sprint 2",
-				"request": req,
-			})
-		})
+	// Market data streams (features.websocket)
+	if stream != nil {
+		registerStreamRoutes(router, stream, cfg.Server.AllowedOrigins)
 	}
 
+	// Admin routes (FR-014)
+	registerAdminRoutes(router, engine)
+
 	return router
 }
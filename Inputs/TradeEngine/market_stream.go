@@ -0,0 +1,643 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - MARKET DATA STREAMS
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Trade Engine Server (WebSocket, FR-006 / FR-016)
+// Description: Per-symbol market data channels (order book depth, public
+//              trades, ticker) fed by a single engine subscription. Each
+//              update is encoded once per channel and handed to every client
+//              subscribed to it; per-channel sequence numbers let clients
+//              detect missed messages and resync.
+// ============================================================================
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mytrader/trade-engine/internal/matching"
+	"github.com/shopspring/decimal"
+)
+
+// Channel kinds: "<kind>.<symbol>", e.g. "trades.BTC/USDT"
+const (
+	channelOrderBook = "orderbook" // "orderbook.BTC/USDT@10"; depth 20 when omitted
+	channelTrades    = "trades"
+	channelTicker    = "ticker"
+)
+
+// streamDepths are the order book depths clients can subscribe to. A small
+// fixed set keeps clients of a symbol on a few shared channels.
+var streamDepths = []int{5, 10, 20, 50, 100}
+
+const defaultStreamDepth = 20 // FR-006
+
+// maxStreamSubscriptions limits the channels of one client
+const maxStreamSubscriptions = 50
+
+// tickerWindow is the period of the ticker statistics
+const tickerWindow = 24 * time.Hour
+
+// Server message types
+const (
+	msgOrderBookSnapshot = "ORDERBOOK_SNAPSHOT"
+	msgOrderBookUpdate   = "ORDERBOOK_UPDATE"
+	msgTrade             = "TRADE"
+	msgTicker            = "TICKER"
+	msgSubscribed        = "SUBSCRIBED"
+	msgUnsubscribed      = "UNSUBSCRIBED"
+	msgError             = "ERROR"
+)
+
+// streamMessage is a message from the server. Sequence counts the updates
+// of Channel: each update carries the previous one's sequence + 1, and the
+// state sent on subscribe carries the sequence of the latest update it
+// includes.
+type streamMessage struct {
+	Type      string      `json:"type"`
+	Channel   string      `json:"channel,omitempty"`
+	Sequence  uint64      `json:"sequence"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data,omitempty"`
+	Message   string      `json:"message,omitempty"` // ERROR
+}
+
+func encodeMessage(msg streamMessage) []byte {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Failed to encode %s message: %v", msg.Type, err)
+		return nil
+	}
+	return data
+}
+
+// depthData is the data of order book messages
+type depthData struct {
+	Symbol string     `json:"symbol"`
+	Bids   [][]string `json:"bids"` // [price, quantity], best price first; "0" removes the level
+	Asks   [][]string `json:"asks"`
+}
+
+// tickerResponse is the TickerResponse schema
+type tickerResponse struct {
+	Symbol                string    `json:"symbol"`
+	LastPrice             string    `json:"last_price"`
+	PriceChange24h        string    `json:"price_change_24h"`
+	PriceChangePercent24h string    `json:"price_change_percent_24h"`
+	High24h               string    `json:"high_24h"`
+	Low24h                string    `json:"low_24h"`
+	Volume24h             string    `json:"volume_24h"`
+	QuoteVolume24h        string    `json:"quote_volume_24h"`
+	BidPrice              string    `json:"bid_price"`
+	AskPrice              string    `json:"ask_price"`
+	Timestamp             time.Time `json:"timestamp"`
+}
+
+// streamClient receives encoded messages. Neither method may block. A
+// client that cannot keep up misses updates, sees a sequence gap and
+// resyncs; the state it then waits for goes through deliverState, which
+// must get it to the client or end the connection.
+type streamClient interface {
+	deliver(msg []byte)
+	deliverState(msg []byte)
+}
+
+// ============================================================================
+// CHANNELS
+// ============================================================================
+
+// streamChannel is a channel and the clients subscribed to it
+type streamChannel struct {
+	name    string
+	seq     uint64
+	clients map[streamClient]struct{}
+
+	// Order book channels: the levels clients have, kept while subscribed
+	depth      int
+	bids, asks []matching.LevelDelta
+}
+
+func newStreamChannel(name string) *streamChannel {
+	return &streamChannel{name: name, clients: make(map[streamClient]struct{})}
+}
+
+func channelName(kind, symbol string, depth int) string {
+	if kind == channelOrderBook {
+		return fmt.Sprintf("%s.%s@%d", kind, symbol, depth)
+	}
+	return kind + "." + symbol
+}
+
+// parseChannel splits a channel name into its kind, symbol and depth
+func parseChannel(name string) (kind, symbol string, depth int, err error) {
+	kind, symbol, ok := strings.Cut(name, ".")
+	if !ok || symbol == "" {
+		return "", "", 0, fmt.Errorf("invalid channel %q: want <kind>.<symbol>", name)
+	}
+
+	switch kind {
+	case channelOrderBook:
+		depth = defaultStreamDepth
+		if s, d, ok := strings.Cut(symbol, "@"); ok {
+			symbol = s
+			if depth, err = strconv.Atoi(d); err != nil || !validStreamDepth(depth) {
+				return "", "", 0, fmt.Errorf("invalid channel %q: depth must be one of %v", name, streamDepths)
+			}
+		}
+	case channelTrades, channelTicker:
+	default:
+		return "", "", 0, fmt.Errorf("invalid channel %q: unknown kind %q", name, kind)
+	}
+	return kind, symbol, depth, nil
+}
+
+func validStreamDepth(depth int) bool {
+	for _, d := range streamDepths {
+		if d == depth {
+			return true
+		}
+	}
+	return false
+}
+
+// ============================================================================
+// SYMBOL STATE
+// ============================================================================
+
+// streamBook is the market data of one symbol
+type streamBook struct {
+	symbol     string
+	bids, asks []matching.LevelDelta // Every level, best price first
+	applied    uint64                // Per-symbol sequence the levels reflect
+	lastPrice  decimal.Decimal
+	stats      rollingStats
+	tickerDue  bool // A trade changed the ticker since it was last sent
+
+	orderbooks map[int]*streamChannel // By depth
+	trades     *streamChannel
+	ticker     *streamChannel
+}
+
+func newStreamBook(symbol string) *streamBook {
+	return &streamBook{
+		symbol:     symbol,
+		orderbooks: make(map[int]*streamChannel),
+		trades:     newStreamChannel(channelName(channelTrades, symbol, 0)),
+		ticker:     newStreamChannel(channelName(channelTicker, symbol, 0)),
+	}
+}
+
+func (b *streamBook) channel(kind string, depth int) *streamChannel {
+	switch kind {
+	case channelTrades:
+		return b.trades
+	case channelTicker:
+		return b.ticker
+	}
+
+	ch, ok := b.orderbooks[depth]
+	if !ok {
+		ch = newStreamChannel(channelName(channelOrderBook, b.symbol, depth))
+		ch.depth = depth
+		b.orderbooks[depth] = ch
+	}
+	return ch
+}
+
+func betterBid(a, b decimal.Decimal) bool { return a.GreaterThan(b) }
+func betterAsk(a, b decimal.Decimal) bool { return a.LessThan(b) }
+
+// applyLevels applies level deltas to levels, both best price first
+func applyLevels(levels, deltas []matching.LevelDelta, better func(a, b decimal.Decimal) bool) []matching.LevelDelta {
+	for _, delta := range deltas {
+		i := sort.Search(len(levels), func(i int) bool { return !better(levels[i].Price, delta.Price) })
+		found := i < len(levels) && levels[i].Price.Equal(delta.Price)
+
+		switch {
+		case delta.Quantity.IsZero():
+			if found {
+				levels = append(levels[:i], levels[i+1:]...)
+			}
+		case found:
+			levels[i] = delta
+		default:
+			levels = append(levels, matching.LevelDelta{})
+			copy(levels[i+1:], levels[i:])
+			levels[i] = delta
+		}
+	}
+	return levels
+}
+
+// topLevels copies the best depth levels
+func topLevels(levels []matching.LevelDelta, depth int) []matching.LevelDelta {
+	if len(levels) > depth {
+		levels = levels[:depth]
+	}
+	return append([]matching.LevelDelta(nil), levels...)
+}
+
+// diffLevels returns the levels of cur that old lacks or holds with another
+// quantity, and the levels of old missing from cur with zero quantity
+func diffLevels(old, cur []matching.LevelDelta, better func(a, b decimal.Decimal) bool) []matching.LevelDelta {
+	changes := make([]matching.LevelDelta, 0)
+	i, j := 0, 0
+	for i < len(old) || j < len(cur) {
+		switch {
+		case j == len(cur) || (i < len(old) && better(old[i].Price, cur[j].Price)):
+			changes = append(changes, matching.LevelDelta{Price: old[i].Price})
+			i++
+		case i == len(old) || better(cur[j].Price, old[i].Price):
+			changes = append(changes, cur[j])
+			j++
+		default:
+			if !old[i].Quantity.Equal(cur[j].Quantity) {
+				changes = append(changes, cur[j])
+			}
+			i++
+			j++
+		}
+	}
+	return changes
+}
+
+func levelPairs(levels []matching.LevelDelta) [][]string {
+	pairs := make([][]string, 0, len(levels))
+	for _, level := range levels {
+		pairs = append(pairs, []string{level.Price.String(), level.Quantity.String()})
+	}
+	return pairs
+}
+
+func (b *streamBook) tickerData(at time.Time) tickerResponse {
+	ticker := tickerResponse{
+		Symbol:    b.symbol,
+		LastPrice: b.lastPrice.String(),
+		BidPrice:  decimal.Zero.String(),
+		AskPrice:  decimal.Zero.String(),
+		Timestamp: at,
+	}
+	if len(b.bids) > 0 {
+		ticker.BidPrice = b.bids[0].Price.String()
+	}
+	if len(b.asks) > 0 {
+		ticker.AskPrice = b.asks[0].Price.String()
+	}
+
+	change, percent := decimal.Zero, decimal.Zero
+	if open := b.stats.open(); open.IsPositive() {
+		change = b.lastPrice.Sub(open)
+		percent = change.Div(open).Mul(decimal.NewFromInt(100))
+	}
+	ticker.PriceChange24h = change.String()
+	ticker.PriceChangePercent24h = percent.StringFixed(2)
+	ticker.High24h = b.stats.high.String()
+	ticker.Low24h = b.stats.low.String()
+	ticker.Volume24h = b.stats.volume.String()
+	ticker.QuoteVolume24h = b.stats.quoteVolume.String()
+	return ticker
+}
+
+// ============================================================================
+// TICKER STATISTICS
+// ============================================================================
+
+// rollingStats aggregates the trades of the last tickerWindow, with one
+// minute resolution
+type rollingStats struct {
+	buckets             []statsBucket // Oldest first
+	volume, quoteVolume decimal.Decimal
+	high, low           decimal.Decimal
+}
+
+type statsBucket struct {
+	start               time.Time
+	open, high, low     decimal.Decimal
+	volume, quoteVolume decimal.Decimal
+}
+
+func (r *rollingStats) add(trade *matching.Trade) {
+	price, quote := trade.Price, trade.Price.Mul(trade.Quantity)
+
+	start := trade.ExecutedAt.Truncate(time.Minute)
+	n := len(r.buckets)
+	if n == 0 || r.buckets[n-1].start.Before(start) {
+		r.buckets = append(r.buckets, statsBucket{start: start, open: price, high: price, low: price})
+		n++
+	}
+
+	// Trades of books running behind count in the latest minute
+	b := &r.buckets[n-1]
+	b.high = decimal.Max(b.high, price)
+	b.low = decimal.Min(b.low, price)
+	b.volume = b.volume.Add(trade.Quantity)
+	b.quoteVolume = b.quoteVolume.Add(quote)
+
+	r.volume = r.volume.Add(trade.Quantity)
+	r.quoteVolume = r.quoteVolume.Add(quote)
+	if n == 1 && r.high.IsZero() {
+		r.high, r.low = price, price
+	}
+	r.high = decimal.Max(r.high, price)
+	r.low = decimal.Min(r.low, price)
+}
+
+// expire drops the minutes that ended before the window ending at now
+func (r *rollingStats) expire(now time.Time) {
+	cutoff := now.Add(-tickerWindow)
+	dropped := 0
+	rescan := false
+	for _, b := range r.buckets {
+		if b.start.Add(time.Minute).After(cutoff) {
+			break
+		}
+		r.volume = r.volume.Sub(b.volume)
+		r.quoteVolume = r.quoteVolume.Sub(b.quoteVolume)
+		rescan = rescan || b.high.Equal(r.high) || b.low.Equal(r.low)
+		dropped++
+	}
+	if dropped == 0 {
+		return
+	}
+	r.buckets = r.buckets[dropped:]
+
+	if rescan {
+		r.high, r.low = decimal.Zero, decimal.Zero
+		for i, b := range r.buckets {
+			if i == 0 {
+				r.high, r.low = b.high, b.low
+			}
+			r.high = decimal.Max(r.high, b.high)
+			r.low = decimal.Min(r.low, b.low)
+		}
+	}
+}
+
+// open returns the first price of the window, zero without trades
+func (r *rollingStats) open() decimal.Decimal {
+	if len(r.buckets) == 0 {
+		return decimal.Zero
+	}
+	return r.buckets[0].open
+}
+
+// ============================================================================
+// STREAM
+// ============================================================================
+
+// marketStream keeps the market data of every symbol from the engine's
+// events and fans each update out to the clients of its channel
+type marketStream struct {
+	engine *matching.MatchingEngine
+	sub    *matching.Subscription
+
+	mu      sync.Mutex
+	books   map[string]*streamBook
+	clients map[streamClient]map[*streamChannel]struct{}
+	seeding bool
+	pending []matching.Event // Published while seeding
+}
+
+// newMarketStream subscribes a stream to the engine's events and seeds it
+// with the current order books
+func newMarketStream(engine *matching.MatchingEngine) (*marketStream, error) {
+	s := &marketStream{
+		engine:  engine,
+		books:   make(map[string]*streamBook),
+		clients: make(map[streamClient]map[*streamChannel]struct{}),
+		seeding: true,
+	}
+
+	sub, err := engine.Subscribe(s, matching.SubscriptionConfig{Backpressure: matching.BackpressureBlock})
+	if err != nil {
+		return nil, err
+	}
+	s.sub = sub
+
+	// Deltas published while a book is read are held back, then applied
+	// unless the copy already includes them
+	seeds := make(map[string]matching.BookLevels)
+	for _, symbol := range engine.Symbols() {
+		if levels, ok := engine.ReadBookLevels(symbol); ok {
+			seeds[symbol] = levels
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for symbol, levels := range seeds {
+		b := s.book(symbol)
+		b.bids, b.asks = levels.Bids, levels.Asks
+		b.applied = levels.LastUpdateID
+		b.lastPrice = levels.LastPrice
+	}
+	for _, event := range s.pending {
+		s.handle(event)
+	}
+	s.seeding, s.pending = false, nil
+
+	return s, nil
+}
+
+// book returns the state of symbol. Books created after seeding start
+// empty, like the engine's. Called under mu.
+func (s *marketStream) book(symbol string) *streamBook {
+	b, ok := s.books[symbol]
+	if !ok {
+		b = newStreamBook(symbol)
+		s.books[symbol] = b
+	}
+	return b
+}
+
+// HandleEvent runs on the subscription's goroutine
+func (s *marketStream) HandleEvent(event matching.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seeding {
+		s.pending = append(s.pending, event)
+		return
+	}
+	s.handle(event)
+}
+
+// handle applies an event and publishes the updates. Called under mu.
+func (s *marketStream) handle(event matching.Event) {
+	switch e := event.(type) {
+	case *matching.TradeEvent:
+		b := s.book(e.Symbol)
+		if e.Sequence > b.applied {
+			b.lastPrice = e.Trade.Price
+		}
+		b.stats.add(e.Trade)
+		b.tickerDue = true
+
+		s.publish(b.trades, msgTrade, e.Timestamp, newPublicTradeResponse(e.Trade))
+
+	case *matching.BookDeltaEvent:
+		b := s.book(e.Symbol)
+		if e.Sequence <= b.applied {
+			return // Included in the seed
+		}
+
+		bestBid, bestAsk := b.best()
+		b.bids = applyLevels(b.bids, e.Bids, betterBid)
+		b.asks = applyLevels(b.asks, e.Asks, betterAsk)
+		b.applied = e.Sequence
+		s.publishDepth(b, e.Timestamp)
+
+		// A command's trades come before its delta: one ticker per command
+		bid, ask := b.best()
+		if b.tickerDue || !bid.Equal(bestBid) || !ask.Equal(bestAsk) {
+			b.tickerDue = false
+			b.stats.expire(e.Timestamp)
+			s.publish(b.ticker, msgTicker, e.Timestamp, b.tickerData(e.Timestamp))
+		}
+	}
+}
+
+// best returns the best bid and ask prices, zero for an empty side
+func (b *streamBook) best() (bid, ask decimal.Decimal) {
+	if len(b.bids) > 0 {
+		bid = b.bids[0].Price
+	}
+	if len(b.asks) > 0 {
+		ask = b.asks[0].Price
+	}
+	return bid, ask
+}
+
+// publishDepth sends every subscribed order book channel of b the levels of
+// its depth that changed. Called under mu.
+func (s *marketStream) publishDepth(b *streamBook, at time.Time) {
+	for _, ch := range b.orderbooks {
+		if len(ch.clients) == 0 {
+			continue
+		}
+
+		bids, asks := topLevels(b.bids, ch.depth), topLevels(b.asks, ch.depth)
+		bidChanges, askChanges := diffLevels(ch.bids, bids, betterBid), diffLevels(ch.asks, asks, betterAsk)
+		ch.bids, ch.asks = bids, asks
+		if len(bidChanges) == 0 && len(askChanges) == 0 {
+			continue
+		}
+
+		s.publish(ch, msgOrderBookUpdate, at, depthData{
+			Symbol: b.symbol,
+			Bids:   levelPairs(bidChanges),
+			Asks:   levelPairs(askChanges),
+		})
+	}
+}
+
+// publish sends the next update of ch to its clients, encoded once.
+// Called under mu.
+func (s *marketStream) publish(ch *streamChannel, typ string, at time.Time, data interface{}) {
+	ch.seq++
+	if len(ch.clients) == 0 {
+		return
+	}
+
+	msg := encodeMessage(streamMessage{Type: typ, Channel: ch.name, Sequence: ch.seq, Timestamp: at, Data: data})
+	for client := range ch.clients {
+		client.deliver(msg)
+	}
+}
+
+// subscribe adds client to a channel and sends it the channel's state: an
+// order book snapshot, the ticker, or SUBSCRIBED for trades. Subscribing
+// again resends the state, which is how clients resync.
+func (s *marketStream) subscribe(client streamClient, name string) error {
+	kind, symbol, depth, err := parseChannel(name)
+	if err != nil {
+		return err
+	}
+	if _, ok := s.engine.GetOrderBook(symbol); !ok {
+		return fmt.Errorf("unknown symbol: %s", symbol)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.book(symbol)
+	ch := b.channel(kind, depth)
+
+	subs := s.clients[client]
+	if _, ok := subs[ch]; !ok {
+		if len(subs) >= maxStreamSubscriptions {
+			return fmt.Errorf("at most %d subscriptions per connection", maxStreamSubscriptions)
+		}
+		if subs == nil {
+			subs = make(map[*streamChannel]struct{})
+			s.clients[client] = subs
+		}
+		subs[ch] = struct{}{}
+
+		// Order book channels without clients are not kept up to date
+		if kind == channelOrderBook && len(ch.clients) == 0 {
+			ch.bids, ch.asks = topLevels(b.bids, depth), topLevels(b.asks, depth)
+		}
+		ch.clients[client] = struct{}{}
+	}
+
+	msg := streamMessage{Type: msgSubscribed, Channel: ch.name, Sequence: ch.seq, Timestamp: time.Now()}
+	switch kind {
+	case channelOrderBook:
+		msg.Type = msgOrderBookSnapshot
+		msg.Data = depthData{Symbol: symbol, Bids: levelPairs(ch.bids), Asks: levelPairs(ch.asks)}
+	case channelTicker:
+		msg.Type = msgTicker
+		b.stats.expire(msg.Timestamp)
+		msg.Data = b.tickerData(msg.Timestamp)
+	}
+	client.deliverState(encodeMessage(msg))
+
+	return nil
+}
+
+// unsubscribe removes client from a channel and confirms with UNSUBSCRIBED
+func (s *marketStream) unsubscribe(client streamClient, name string) error {
+	kind, symbol, depth, err := parseChannel(name)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = channelName(kind, symbol, depth)
+	if b, ok := s.books[symbol]; ok {
+		ch := b.channel(kind, depth)
+		delete(ch.clients, client)
+		delete(s.clients[client], ch)
+	}
+
+	client.deliver(encodeMessage(streamMessage{Type: msgUnsubscribed, Channel: name, Timestamp: time.Now()}))
+	return nil
+}
+
+// unsubscribeAll removes a client from every channel. No message reaches the
+// client afterwards.
+func (s *marketStream) unsubscribeAll(client streamClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.clients[client] {
+		delete(ch.clients, client)
+	}
+	delete(s.clients, client)
+}
+
+// close unsubscribes the stream from the engine
+func (s *marketStream) close() {
+	s.sub.Close()
+}
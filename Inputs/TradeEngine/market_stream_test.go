@@ -0,0 +1,308 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - MARKET DATA STREAM TESTS
+// ============================================================================
+
+package main
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mytrader/trade-engine/internal/matching"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var streamEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// recordingClient keeps the messages it receives
+type recordingClient struct {
+	mu   sync.Mutex
+	msgs [][]byte
+}
+
+func (c *recordingClient) deliver(msg []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.msgs = append(c.msgs, msg)
+}
+
+func (c *recordingClient) deliverState(msg []byte) {
+	c.deliver(msg)
+}
+
+type testMessage struct {
+	Type     string          `json:"type"`
+	Channel  string          `json:"channel"`
+	Sequence uint64          `json:"sequence"`
+	Data     json.RawMessage `json:"data"`
+	Message  string          `json:"message"`
+}
+
+func (m testMessage) decode(t *testing.T, v interface{}) {
+	require.NoError(t, json.Unmarshal(m.Data, v), string(m.Data))
+}
+
+func (m testMessage) depth(t *testing.T) depthData {
+	var data depthData
+	m.decode(t, &data)
+	return data
+}
+
+func (m testMessage) ticker(t *testing.T) tickerResponse {
+	var data tickerResponse
+	m.decode(t, &data)
+	return data
+}
+
+// take returns the messages received since the last call, including the
+// updates of every event published before the call
+func (c *recordingClient) take(t *testing.T, s *marketStream) []testMessage {
+	s.sub.Flush()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	msgs := make([]testMessage, 0, len(c.msgs))
+	for _, raw := range c.msgs {
+		var msg testMessage
+		require.NoError(t, json.Unmarshal(raw, &msg), string(raw))
+		msgs = append(msgs, msg)
+	}
+	c.msgs = nil
+	return msgs
+}
+
+func newTestStream(t *testing.T, engine *matching.MatchingEngine) *marketStream {
+	s, err := newMarketStream(engine)
+	require.NoError(t, err)
+	t.Cleanup(s.close)
+	return s
+}
+
+func newStreamEngine(t *testing.T) (*matching.MatchingEngine, *matching.FakeClock) {
+	clock := matching.NewFakeClock(streamEpoch)
+	engine := matching.NewMatchingEngine(matching.WithClock(clock))
+	t.Cleanup(engine.Close)
+	engine.GetOrCreateOrderBook("BTC/USDT")
+	return engine, clock
+}
+
+func limitOrder(t *testing.T, engine *matching.MatchingEngine, user string, side matching.Side, quantity, price string) []*matching.Trade {
+	trades, err := engine.PlaceOrder(&matching.Order{
+		UserID:      user,
+		Symbol:      "BTC/USDT",
+		Side:        side,
+		OrderType:   matching.OrderTypeLimit,
+		TimeInForce: matching.TimeInForceGTC,
+		Quantity:    decimal.RequireFromString(quantity),
+		Price:       decimal.RequireFromString(price),
+	})
+	require.NoError(t, err)
+	return trades
+}
+
+func TestMarketStream_OrderBook(t *testing.T) {
+	engine, _ := newStreamEngine(t)
+
+	// Resting before the stream starts: seeded
+	for _, price := range []string{"50100", "50200", "50300", "50400", "50500", "50600"} {
+		limitOrder(t, engine, "alice", matching.SideSell, "1", price)
+	}
+	limitOrder(t, engine, "bob", matching.SideBuy, "2", "49900")
+
+	s := newTestStream(t, engine)
+	top5, top20 := &recordingClient{}, &recordingClient{}
+	require.NoError(t, s.subscribe(top5, "orderbook.BTC/USDT@5"))
+	require.NoError(t, s.subscribe(top20, "orderbook.BTC/USDT"))
+
+	msgs := top5.take(t, s)
+	require.Equal(t, 1, len(msgs))
+	assert.Equal(t, msgOrderBookSnapshot, msgs[0].Type)
+	assert.Equal(t, "orderbook.BTC/USDT@5", msgs[0].Channel)
+	assert.Equal(t, uint64(0), msgs[0].Sequence)
+	depth := msgs[0].depth(t)
+	assert.Equal(t, [][]string{{"49900", "2"}}, depth.Bids)
+	assert.Equal(t, 5, len(depth.Asks))
+	assert.Equal(t, []string{"50100", "1"}, depth.Asks[0])
+
+	msgs = top20.take(t, s)
+	require.Equal(t, 1, len(msgs))
+	assert.Equal(t, "orderbook.BTC/USDT@20", msgs[0].Channel)
+	assert.Equal(t, 6, len(msgs[0].depth(t).Asks))
+
+	// The best ask fills: the sixth level enters the top 5
+	limitOrder(t, engine, "bob", matching.SideBuy, "1", "50100")
+
+	msgs = top5.take(t, s)
+	require.Equal(t, 1, len(msgs))
+	assert.Equal(t, msgOrderBookUpdate, msgs[0].Type)
+	assert.Equal(t, uint64(1), msgs[0].Sequence)
+	depth = msgs[0].depth(t)
+	assert.Empty(t, depth.Bids)
+	assert.Equal(t, [][]string{{"50100", "0"}, {"50600", "1"}}, depth.Asks)
+
+	msgs = top20.take(t, s)
+	require.Equal(t, 1, len(msgs))
+	assert.Equal(t, [][]string{{"50100", "0"}}, msgs[0].depth(t).Asks)
+
+	// A change below the top 5 only reaches the deeper channel
+	limitOrder(t, engine, "carol", matching.SideSell, "3", "50700")
+	assert.Empty(t, top5.take(t, s))
+	msgs = top20.take(t, s)
+	require.Equal(t, 1, len(msgs))
+	assert.Equal(t, uint64(2), msgs[0].Sequence)
+	assert.Equal(t, [][]string{{"50700", "3"}}, msgs[0].depth(t).Asks)
+
+	// Resync: the snapshot carries the sequence of the latest update
+	require.NoError(t, s.subscribe(top5, "orderbook.BTC/USDT@5"))
+	msgs = top5.take(t, s)
+	require.Equal(t, 1, len(msgs))
+	assert.Equal(t, msgOrderBookSnapshot, msgs[0].Type)
+	assert.Equal(t, uint64(1), msgs[0].Sequence)
+	assert.Equal(t, []string{"50600", "1"}, msgs[0].depth(t).Asks[4])
+
+	// The mirror matches the engine's book
+	levels, ok := engine.ReadBookLevels("BTC/USDT")
+	require.True(t, ok)
+	s.mu.Lock()
+	b := s.books["BTC/USDT"]
+	assert.Equal(t, levelPairs(levels.Asks), levelPairs(b.asks))
+	assert.Equal(t, levelPairs(levels.Bids), levelPairs(b.bids))
+	assert.Equal(t, levels.LastUpdateID, b.applied)
+	s.mu.Unlock()
+}
+
+func TestMarketStream_FanOut(t *testing.T) {
+	engine, _ := newStreamEngine(t)
+	s := newTestStream(t, engine)
+
+	a, b := &recordingClient{}, &recordingClient{}
+	for _, client := range []*recordingClient{a, b} {
+		require.NoError(t, s.subscribe(client, "trades.BTC/USDT"))
+	}
+	msgs := a.take(t, s)
+	require.Equal(t, 1, len(msgs))
+	assert.Equal(t, msgSubscribed, msgs[0].Type)
+	b.take(t, s)
+
+	limitOrder(t, engine, "alice", matching.SideSell, "1", "50000")
+	trades := limitOrder(t, engine, "bob", matching.SideBuy, "1", "50000")
+	require.Equal(t, 1, len(trades))
+	s.sub.Flush()
+
+	// Both clients share one encoded message
+	a.mu.Lock()
+	b.mu.Lock()
+	require.Equal(t, 1, len(a.msgs))
+	require.Equal(t, 1, len(b.msgs))
+	assert.Same(t, &a.msgs[0][0], &b.msgs[0][0])
+	a.mu.Unlock()
+	b.mu.Unlock()
+
+	msgs = a.take(t, s)
+	assert.Equal(t, msgTrade, msgs[0].Type)
+	assert.Equal(t, uint64(1), msgs[0].Sequence)
+	var trade publicTradeResponse
+	msgs[0].decode(t, &trade)
+	assert.Equal(t, trades[0].TradeID, trade.TradeID)
+	assert.Equal(t, "50000", trade.Price)
+
+	// Unsubscribed clients get no more updates
+	require.NoError(t, s.unsubscribe(b, "trades.BTC/USDT"))
+	s.unsubscribeAll(a)
+	msgs = b.take(t, s)
+	require.Equal(t, 2, len(msgs))
+	assert.Equal(t, msgUnsubscribed, msgs[1].Type)
+	assert.Equal(t, "trades.BTC/USDT", msgs[1].Channel)
+
+	limitOrder(t, engine, "alice", matching.SideSell, "1", "50000")
+	limitOrder(t, engine, "bob", matching.SideBuy, "1", "50000")
+	assert.Empty(t, a.take(t, s))
+	assert.Empty(t, b.take(t, s))
+
+	// Sequences count the updates missed meanwhile
+	require.NoError(t, s.subscribe(a, "trades.BTC/USDT"))
+	msgs = a.take(t, s)
+	assert.Equal(t, uint64(2), msgs[0].Sequence)
+}
+
+func TestMarketStream_Ticker(t *testing.T) {
+	engine, clock := newStreamEngine(t)
+	s := newTestStream(t, engine)
+
+	client := &recordingClient{}
+	require.NoError(t, s.subscribe(client, "ticker.BTC/USDT"))
+	msgs := client.take(t, s)
+	require.Equal(t, 1, len(msgs))
+	assert.Equal(t, msgTicker, msgs[0].Type)
+	ticker := msgs[0].ticker(t)
+	assert.Equal(t, "0", ticker.LastPrice)
+	assert.Equal(t, "0", ticker.Volume24h)
+
+	// latest places a trade at price and returns the ticker it sends
+	latest := func(price string) tickerResponse {
+		limitOrder(t, engine, "alice", matching.SideSell, "1", price)
+		limitOrder(t, engine, "bob", matching.SideBuy, "1", price)
+		msgs := client.take(t, s)
+		require.NotEmpty(t, msgs)
+		return msgs[len(msgs)-1].ticker(t)
+	}
+
+	latest("50000")
+	clock.Set(streamEpoch.Add(time.Hour))
+	ticker = latest("51000")
+	assert.Equal(t, "51000", ticker.LastPrice)
+	assert.Equal(t, "1000", ticker.PriceChange24h)
+	assert.Equal(t, "2.00", ticker.PriceChangePercent24h)
+	assert.Equal(t, "51000", ticker.High24h)
+	assert.Equal(t, "50000", ticker.Low24h)
+	assert.Equal(t, "2", ticker.Volume24h)
+	assert.Equal(t, "101000", ticker.QuoteVolume24h)
+	assert.Equal(t, "0", ticker.BidPrice)
+	assert.Equal(t, "0", ticker.AskPrice)
+
+	// A quote change updates the ticker without a trade
+	limitOrder(t, engine, "carol", matching.SideBuy, "1", "48000")
+	msgs = client.take(t, s)
+	require.Equal(t, 1, len(msgs))
+	assert.Equal(t, "48000", msgs[0].ticker(t).BidPrice)
+
+	// The first trade leaves the 24h window
+	clock.Set(streamEpoch.Add(tickerWindow + time.Minute))
+	ticker = latest("49000")
+	assert.Equal(t, "-2000", ticker.PriceChange24h)
+	assert.Equal(t, "-3.92", ticker.PriceChangePercent24h)
+	assert.Equal(t, "51000", ticker.High24h)
+	assert.Equal(t, "49000", ticker.Low24h)
+	assert.Equal(t, "2", ticker.Volume24h)
+	assert.Equal(t, "100000", ticker.QuoteVolume24h)
+}
+
+func TestMarketStream_SubscribeErrors(t *testing.T) {
+	engine, _ := newStreamEngine(t)
+	s := newTestStream(t, engine)
+	client := &recordingClient{}
+
+	for _, channel := range []string{
+		"BTC/USDT",
+		"orderbook.",
+		"candles.BTC/USDT",
+		"orderbook.BTC/USDT@7",
+		"orderbook.BTC/USDT@x",
+		"trades.DOGE/USDT",
+	} {
+		assert.Error(t, s.subscribe(client, channel), channel)
+	}
+	assert.Empty(t, client.take(t, s))
+
+	// Books created after the stream start empty
+	engine.GetOrCreateOrderBook("ETH/USDT")
+	require.NoError(t, s.subscribe(client, "orderbook.ETH/USDT@10"))
+	msgs := client.take(t, s)
+	require.Equal(t, 1, len(msgs))
+	assert.Empty(t, msgs[0].depth(t).Asks)
+}
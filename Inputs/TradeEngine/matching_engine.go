@@ -12,13 +12,13 @@
 package matching
 
 import (
-	"container/heap"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
@@ -39,6 +39,10 @@ const (
 	OrderTypeMarket OrderType = "MARKET"
 	OrderTypeLimit  OrderType = "LIMIT"
 	OrderTypeStop   OrderType = "STOP"
+
+	// Phase 2 (Requirements 4.4)
+	OrderTypeStopLimit    OrderType = "STOP_LIMIT"    // Becomes LIMIT at Price when triggered
+	OrderTypeTrailingStop OrderType = "TRAILING_STOP" // StopPrice follows the market by TrailingOffset
 )
 
 type OrderStatus string
@@ -47,6 +51,7 @@ const (
 	OrderStatusPending         OrderStatus = "PENDING"
 	OrderStatusOpen            OrderStatus = "OPEN"
 	OrderStatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	OrderStatusTriggered       OrderStatus = "TRIGGERED" // Stop order converted to market
 	OrderStatusFilled          OrderStatus = "FILLED"
 	OrderStatusCancelled       OrderStatus = "CANCELLED"
 	OrderStatusRejected        OrderStatus = "REJECTED"
@@ -60,6 +65,29 @@ const (
 	TimeInForceFOK TimeInForce = "FOK" // Fill or Kill
 )
 
+// STPMode selects self-trade prevention when an incoming order would match
+// a resting order of the same user (RMR-005). The incoming order's mode applies.
+type STPMode string
+
+const (
+	STPModeNone               STPMode = ""                     // Self-trades allowed
+	STPModeCancelNewest       STPMode = "CANCEL_NEWEST"        // Cancel the incoming order
+	STPModeCancelOldest       STPMode = "CANCEL_OLDEST"        // Cancel the resting order
+	STPModeCancelBoth         STPMode = "CANCEL_BOTH"          // Cancel both orders
+	STPModeDecrementAndCancel STPMode = "DECREMENT_AND_CANCEL" // Reduce both by the overlap
+)
+
+// StatusReason explains a status change the engine made on its own
+type StatusReason string
+
+const (
+	StatusReasonNone            StatusReason = ""
+	StatusReasonSelfTrade       StatusReason = "SELF_TRADE_PREVENTION"
+	StatusReasonPriceProtection StatusReason = "PRICE_PROTECTION" // Market order stopped at the price band
+	StatusReasonDelisted        StatusReason = "SYMBOL_DELISTED"
+	StatusReasonCircuitBreaker  StatusReason = "CIRCUIT_BREAKER" // Taker remainder left when a trade halted the symbol
+)
+
 // ============================================================================
 // ORDER STRUCTURE
 // ============================================================================
@@ -75,13 +103,30 @@ type Order struct {
 	FilledQuantity   decimal.Decimal `json:"filled_quantity"`
 	Price            decimal.Decimal `json:"price"`            // Nil for MARKET
 	StopPrice        decimal.Decimal `json:"stop_price"`       // For STOP orders
+	TrailingOffset   decimal.Decimal `json:"trailing_offset"`  // For TRAILING_STOP orders
+	TrailingPercent  bool            `json:"trailing_percent"` // TrailingOffset is a percentage
 	TimeInForce      TimeInForce     `json:"time_in_force"`
+	STPMode          STPMode         `json:"stp_mode,omitempty"`
+	StatusReason     StatusReason    `json:"status_reason,omitempty"`
 	ClientOrderID    string          `json:"client_order_id"`
 	CreatedAt        time.Time       `json:"created_at"`
 	UpdatedAt        time.Time       `json:"updated_at"`
 	
+	// Sequence of the latest update of this order
+	EventSequence
+	
 	// Internal fields
-	index int // Heap index for priority queue
+	level    *PriceLevel     // Level the order rests in (nil when not resting)
+	prev     *Order          // FIFO neighbours within level
+	next     *Order
+	trailRef decimal.Decimal // Best trade price seen by a trailing stop
+	stopSeq  uint64          // Arrival number in the stop watchlist
+	
+	// Fixed-point mirrors of Price, Quantity and FilledQuantity (only
+	// maintained in fixed-point books, see fixed_point.go)
+	fxPrice  int64
+	fxQty    int64
+	fxFilled int64
 }
 
 // RemainingQuantity returns unfilled quantity
@@ -94,6 +139,11 @@ func (o *Order) IsFilled() bool {
 	return o.FilledQuantity.Equal(o.Quantity)
 }
 
+// fxRemaining returns unfilled quantity in lots (fixed-point books)
+func (o *Order) fxRemaining() int64 {
+	return o.fxQty - o.fxFilled
+}
+
 // ============================================================================
 // TRADE STRUCTURE
 // ============================================================================
@@ -105,103 +155,114 @@ type Trade struct {
 	SellerOrderID   string          `json:"seller_order_id"`
 	BuyerUserID     string          `json:"buyer_user_id"`
 	SellerUserID    string          `json:"seller_user_id"`
+	TakerOrderID    string          `json:"taker_order_id"` // Aggressor (incoming) order
+	MakerOrderID    string          `json:"maker_order_id"` // Resting order
+	AggressorSide   Side            `json:"aggressor_side"`
 	Price           decimal.Decimal `json:"price"`
 	Quantity        decimal.Decimal `json:"quantity"`
 	BuyerFee        decimal.Decimal `json:"buyer_fee"`
 	SellerFee       decimal.Decimal `json:"seller_fee"`
 	IsBuyerMaker    bool            `json:"is_buyer_maker"`
 	ExecutedAt      time.Time       `json:"executed_at"`
+	
+	EventSequence
 }
 
 // ============================================================================
 // PRICE LEVEL (Order aggregation at same price)
 // ============================================================================
 
+// PriceLevel keeps its FIFO queue as an intrusive doubly-linked list through
+// the orders themselves, so removing any order (cancel or fill) is O(1)
 type PriceLevel struct {
 	Price    decimal.Decimal
-	Orders   []*Order // FIFO queue at this price
 	Quantity decimal.Decimal // Total quantity at this level
+	
+	head  *Order // Oldest order (first to match)
+	tail  *Order // Newest order
+	count int
+	
+	fxPrice    int64 // Price in ticks (fixed-point books)
+	fxQuantity int64 // Quantity in lots (fixed-point books)
+	fixed      bool  // Maintains fxQuantity; Quantity is stored by the book
 }
 
 func NewPriceLevel(price decimal.Decimal) *PriceLevel {
 	return &PriceLevel{
 		Price:    price,
-		Orders:   make([]*Order, 0),
 		Quantity: decimal.Zero,
 	}
 }
 
 func (pl *PriceLevel) AddOrder(order *Order) {
-	pl.Orders = append(pl.Orders, order)
-	pl.Quantity = pl.Quantity.Add(order.RemainingQuantity())
-}
-
-func (pl *PriceLevel) RemoveOrder(orderID string) bool {
-	for i, order := range pl.Orders {
-		if order.OrderID == orderID {
-			pl.Quantity = pl.Quantity.Sub(order.RemainingQuantity())
-			pl.Orders = append(pl.Orders[:i], pl.Orders[i+1:]...)
-			return true
-		}
+	order.level = pl
+	order.prev = pl.tail
+	order.next = nil
+	
+	if pl.tail != nil {
+		pl.tail.next = order
+	} else {
+		pl.head = order
 	}
-	return false
-}
-
-func (pl *PriceLevel) IsEmpty() bool {
-	return len(pl.Orders) == 0
-}
-
-// ============================================================================
-// PRIORITY QUEUE (Min-Heap for Asks, Max-Heap for Bids)
-// ============================================================================
-
-type PriceQueue struct {
-	levels []*PriceLevel
-	isAsk  bool // true for ask (min-heap), false for bid (max-heap)
-}
-
-func NewPriceQueue(isAsk bool) *PriceQueue {
-	pq := &PriceQueue{
-		levels: make([]*PriceLevel, 0),
-		isAsk:  isAsk,
+	pl.tail = order
+	pl.count++
+	
+	if pl.fixed {
+		pl.fxQuantity += order.fxRemaining()
+	} else {
+		pl.Quantity = pl.Quantity.Add(order.RemainingQuantity())
 	}
-	heap.Init(pq)
-	return pq
 }
 
-// Implement heap.Interface
-func (pq PriceQueue) Len() int { return len(pq.levels) }
-
-func (pq PriceQueue) Less(i, j int) bool {
-	if pq.isAsk {
-		// Min-heap for asks (lowest price first)
-		return pq.levels[i].Price.LessThan(pq.levels[j].Price)
+// RemoveOrder unlinks order from the level; false if it doesn't rest here
+func (pl *PriceLevel) RemoveOrder(order *Order) bool {
+	if order.level != pl {
+		return false
+	}
+	
+	if order.prev != nil {
+		order.prev.next = order.next
+	} else {
+		pl.head = order.next
+	}
+	if order.next != nil {
+		order.next.prev = order.prev
+	} else {
+		pl.tail = order.prev
 	}
-	// Max-heap for bids (highest price first)
-	return pq.levels[i].Price.GreaterThan(pq.levels[j].Price)
+	
+	order.level, order.prev, order.next = nil, nil, nil
+	pl.count--
+	
+	if pl.fixed {
+		pl.fxQuantity -= order.fxRemaining()
+	} else {
+		pl.Quantity = pl.Quantity.Sub(order.RemainingQuantity())
+	}
+	return true
 }
 
-func (pq PriceQueue) Swap(i, j int) {
-	pq.levels[i], pq.levels[j] = pq.levels[j], pq.levels[i]
+// Front returns the oldest order at this level
+func (pl *PriceLevel) Front() *Order {
+	return pl.head
 }
 
-func (pq *PriceQueue) Push(x interface{}) {
-	pq.levels = append(pq.levels, x.(*PriceLevel))
+// Len returns the number of orders at this level
+func (pl *PriceLevel) Len() int {
+	return pl.count
 }
 
-func (pq *PriceQueue) Pop() interface{} {
-	old := pq.levels
-	n := len(old)
-	item := old[n-1]
-	pq.levels = old[0 : n-1]
-	return item
+// Orders returns the level's orders in FIFO order
+func (pl *PriceLevel) Orders() []*Order {
+	orders := make([]*Order, 0, pl.count)
+	for order := pl.head; order != nil; order = order.next {
+		orders = append(orders, order)
+	}
+	return orders
 }
 
-func (pq *PriceQueue) Peek() *PriceLevel {
-	if len(pq.levels) == 0 {
-		return nil
-	}
-	return pq.levels[0]
+func (pl *PriceLevel) IsEmpty() bool {
+	return pl.count == 0
 }
 
 // ============================================================================
@@ -210,25 +271,58 @@ func (pq *PriceQueue) Peek() *PriceLevel {
 
 type OrderBook struct {
 	Symbol     string
-	Bids       *PriceQueue            // Buy orders (max-heap)
-	Asks       *PriceQueue            // Sell orders (min-heap)
-	Orders     map[string]*Order      // Order ID -> Order
-	PriceLevels map[string]*PriceLevel // Price -> PriceLevel (for quick access)
+	Bids       *PriceQueue       // Buy orders (highest price first)
+	Asks       *PriceQueue       // Sell orders (lowest price first)
+	Orders     map[string]*Order // Order ID -> Order
+	StopOrders []*Order          // Untriggered stop orders (watchlist, arrival order)
+	stops      stopWatchlist     // StopOrders by trigger price (see stop_orders.go)
 	mu         sync.RWMutex
 	
+	// Single writer: commands run on the book's goroutine (book_actor.go)
+	commands  chan bookCommand
+	queueSize int
+	startOnce sync.Once
+	sendMu    sync.RWMutex // Guards closed against sends on the closed queue
+	closed    bool
+	stopped   chan struct{}
+	
+	// Trading rules (tick size, lot size, limits)
+	Spec SymbolSpec
+	
+	// Fixed-point scale; nil matches on decimals (see fixed_point.go)
+	fx        *FixedScale
+	fxRules   fixedRules // Spec in ticks and lots
+	makerRate fixedRate  // Fee rates of the last trade
+	takerRate fixedRate
+	
+	// Trading status and volatility halt (owned by the book goroutine)
+	Status  SymbolStatus
+	Breaker CircuitBreaker
+	
 	// Statistics
 	LastPrice      decimal.Decimal
 	LastUpdateTime time.Time
+	
+	seq uint64    // Per-symbol sequence of the latest event (see sequence.go)
+	now   time.Time // Time of the command being executed (see book_actor.go)
+	clock Clock     // Time source of commands (the engine's clock)
+	
+	// Levels changed by the running command, published as a book delta
+	// once it completes (see events.go)
+	changed      []levelChange
+	publishDelta func()
 }
 
 func NewOrderBook(symbol string) *OrderBook {
 	return &OrderBook{
 		Symbol:      symbol,
-		Bids:        NewPriceQueue(false), // Max-heap
-		Asks:        NewPriceQueue(true),  // Min-heap
-		Orders:      make(map[string]*Order),
-		PriceLevels: make(map[string]*PriceLevel),
-		LastPrice:   decimal.Zero,
+		Bids:       NewPriceQueue(false),
+		Asks:       NewPriceQueue(true),
+		Orders:     make(map[string]*Order),
+		StopOrders: make([]*Order, 0),
+		Status:     SymbolStatusActive,
+		LastPrice:  decimal.Zero,
+		clock:      SystemClock{},
 	}
 }
 
@@ -250,24 +344,25 @@ func (ob *OrderBook) AddOrder(order *Order) error {
 	ob.Orders[order.OrderID] = order
 	
 	// Find or create price level
-	priceKey := order.Price.String()
-	priceLevel, exists := ob.PriceLevels[priceKey]
+	queue := ob.sideQueue(order.Side)
+	var priceLevel *PriceLevel
+	if ob.fx != nil {
+		priceLevel = queue.getTicks(order.fxPrice)
+	} else {
+		priceLevel = queue.Get(order.Price)
+	}
 	
-	if !exists {
+	if priceLevel == nil {
 		priceLevel = NewPriceLevel(order.Price)
-		ob.PriceLevels[priceKey] = priceLevel
-		
-		// Add price level to appropriate queue
-		if order.Side == SideBuy {
-			heap.Push(ob.Bids, priceLevel)
-		} else {
-			heap.Push(ob.Asks, priceLevel)
-		}
+		priceLevel.fxPrice, priceLevel.fixed = order.fxPrice, ob.fx != nil
+		queue.Insert(priceLevel)
 	}
 	
 	// Add order to price level
 	priceLevel.AddOrder(order)
-	ob.LastUpdateTime = time.Now()
+	ob.storeFixed(priceLevel)
+	ob.touch(order.Side, priceLevel)
+	ob.LastUpdateTime = ob.commandTime()
 	
 	return nil
 }
@@ -283,26 +378,50 @@ func (ob *OrderBook) RemoveOrder(orderID string) error {
 	}
 	
 	// Remove from price level
-	priceKey := order.Price.String()
-	priceLevel := ob.PriceLevels[priceKey]
-	
-	if priceLevel != nil {
-		priceLevel.RemoveOrder(orderID)
+	if priceLevel := order.level; priceLevel != nil {
+		priceLevel.RemoveOrder(order)
+		ob.touch(order.Side, priceLevel)
 		
 		// Remove price level if empty
 		if priceLevel.IsEmpty() {
-			delete(ob.PriceLevels, priceKey)
-			// Note: Heap cleanup happens during matching
+			ob.removeLevel(ob.sideQueue(order.Side), priceLevel)
+		} else {
+			ob.storeFixed(priceLevel)
 		}
 	}
 	
 	// Remove from orders map
 	delete(ob.Orders, orderID)
-	ob.LastUpdateTime = time.Now()
+	ob.LastUpdateTime = ob.commandTime()
 	
 	return nil
 }
 
+// removeLevel drops an empty level from queue
+func (ob *OrderBook) removeLevel(queue *PriceQueue, level *PriceLevel) {
+	if ob.fx != nil {
+		queue.removeTicks(level.fxPrice)
+	} else {
+		queue.Remove(level.Price)
+	}
+}
+
+// sideQueue returns the price levels holding orders of side
+func (ob *OrderBook) sideQueue(side Side) *PriceQueue {
+	if side == SideBuy {
+		return ob.Bids
+	}
+	return ob.Asks
+}
+
+// GetSpec returns the symbol's trading rules
+func (ob *OrderBook) GetSpec() SymbolSpec {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+	
+	return ob.Spec
+}
+
 // GetBestBid returns the highest bid price
 func (ob *OrderBook) GetBestBid() decimal.Decimal {
 	ob.mu.RLock()
@@ -332,35 +451,23 @@ func (ob *OrderBook) GetDepth(levels int) (bids, asks [][]string) {
 	ob.mu.RLock()
 	defer ob.mu.RUnlock()
 	
-	// Get bids
-	bids = make([][]string, 0, levels)
-	bidCount := 0
-	for _, level := range ob.Bids.levels {
-		if bidCount >= levels {
-			break
-		}
-		bids = append(bids, []string{
-			level.Price.String(),
-			level.Quantity.String(),
-		})
-		bidCount++
-	}
-	
-	// Get asks
-	asks = make([][]string, 0, levels)
-	askCount := 0
-	for _, level := range ob.Asks.levels {
-		if askCount >= levels {
-			break
+	return depthOf(ob.Bids, levels), depthOf(ob.Asks, levels)
+}
+
+// depthOf returns up to n [price, quantity] pairs, best price first
+func depthOf(queue *PriceQueue, n int) [][]string {
+	depth := make([][]string, 0, n)
+	queue.Each(func(level *PriceLevel) bool {
+		if len(depth) >= n {
+			return false
 		}
-		asks = append(asks, []string{
+		depth = append(depth, []string{
 			level.Price.String(),
 			level.Quantity.String(),
 		})
-		askCount++
-	}
-	
-	return bids, asks
+		return true
+	})
+	return depth
 }
 
 // ============================================================================
@@ -406,24 +513,44 @@ type MatchingEngine struct {
 	OrderBooks map[string]*OrderBook // Symbol -> OrderBook
 	mu         sync.RWMutex
 	
+	// Pending commands per order book (0 = DefaultCommandQueueSize)
+	CommandQueueSize int
+	
+	globalSeq atomic.Uint64 // Engine-wide event sequence
+	journal   *Journal      // Write-ahead command journal (see journal.go)
+	
+	restoredSeq uint64 // Journal entry the restored snapshot covers (see snapshot.go)
+	
+	// Time source and order / trade IDs (see clock.go)
+	clock    Clock
+	orderIDs IDGenerator
+	tradeIDs IDGenerator // nil: derived from the fill
+	
 	// Fee configuration
 	MakerFee decimal.Decimal
 	TakerFee decimal.Decimal
 	
-	// Callbacks
-	OnTrade func(trade *Trade)
-	OnOrderUpdate func(order *Order)
+	// Event subscribers (see events.go)
+	bus eventBus
 }
 
-func NewMatchingEngine() *MatchingEngine {
-	return &MatchingEngine{
+func NewMatchingEngine(opts ...EngineOption) *MatchingEngine {
+	me := &MatchingEngine{
 		OrderBooks: make(map[string]*OrderBook),
 		MakerFee:   decimal.NewFromFloat(0.0005), // 0.05%
 		TakerFee:   decimal.NewFromFloat(0.0010), // 0.10%
+		clock:      SystemClock{},
+		orderIDs:   UUIDGenerator{},
+	}
+	for _, opt := range opts {
+		opt(me)
 	}
+	return me
 }
 
-// GetOrCreateOrderBook returns order book for symbol
+// GetOrCreateOrderBook returns order book for symbol, creating it. Symbol
+// setup only: commands on a symbol without a book are rejected with
+// ErrCodeUnknownSymbol.
 func (me *MatchingEngine) GetOrCreateOrderBook(symbol string) *OrderBook {
 	me.mu.Lock()
 	defer me.mu.Unlock()
@@ -431,37 +558,105 @@ func (me *MatchingEngine) GetOrCreateOrderBook(symbol string) *OrderBook {
 	ob, exists := me.OrderBooks[symbol]
 	if !exists {
 		ob = NewOrderBook(symbol)
+		ob.queueSize = me.CommandQueueSize
+		ob.clock = me.clock
+		ob.publishDelta = func() { me.emitBookDelta(ob) }
 		me.OrderBooks[symbol] = ob
 	}
 	
 	return ob
 }
 
+// GetOrderBook returns the order book for symbol without creating it
+func (me *MatchingEngine) GetOrderBook(symbol string) (*OrderBook, bool) {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+	
+	ob, exists := me.OrderBooks[symbol]
+	return ob, exists
+}
+
+// Symbols returns the symbols that have an order book
+func (me *MatchingEngine) Symbols() []string {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+	
+	symbols := make([]string, 0, len(me.OrderBooks))
+	for symbol := range me.OrderBooks {
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
 // PlaceOrder places a new order and attempts to match it
 func (me *MatchingEngine) PlaceOrder(order *Order) ([]*Trade, error) {
-	// Validate order
+	return me.SubmitOrder(order).Wait()
+}
+
+// placeOrder validates an order and matches it against ob. Runs on the
+// book's goroutine.
+func (me *MatchingEngine) placeOrder(order *Order, ob *OrderBook) ([]*Trade, error) {
+	order.Status = OrderStatusOpen
+	order.CreatedAt = ob.now
+	order.UpdatedAt = ob.now
+	order.FilledQuantity = decimal.Zero
+	
 	if err := me.validateOrder(order); err != nil {
 		order.Status = OrderStatusRejected
 		return nil, err
 	}
 	
-	order.Status = OrderStatusOpen
-	order.CreatedAt = time.Now()
-	order.UpdatedAt = time.Now()
-	order.FilledQuantity = decimal.Zero
+	// Symbol status: halted / maintenance / delisted books restrict orders
+	if err := me.checkOrderAccepted(ob, order, ob.now); err != nil {
+		order.Status = OrderStatusRejected
+		return nil, err
+	}
+	
+	if err := ob.loadFixed(order); err != nil {
+		order.Status = OrderStatusRejected
+		return nil, err
+	}
+	
+	// Per-symbol trading rules
+	if err := ob.validateSpec(order); err != nil {
+		order.Status = OrderStatusRejected
+		return nil, err
+	}
 	
-	// Get order book
-	ob := me.GetOrCreateOrderBook(order.Symbol)
+	// Price band (RMR-004). Stop-limit prices are checked again when they
+	// trigger.
+	if order.OrderType == OrderTypeLimit || order.OrderType == OrderTypeStopLimit {
+		if err := ob.checkPriceBand(order.Price); err != nil {
+			order.Status = OrderStatusRejected
+			return nil, err
+		}
+	}
 	
 	// Match order
 	var trades []*Trade
 	var err error
 	
 	switch order.OrderType {
-	case OrderTypeMarket:
-		trades, err = me.matchMarketOrder(order, ob)
-	case OrderTypeLimit:
-		trades, err = me.matchLimitOrder(order, ob)
+	case OrderTypeMarket, OrderTypeLimit:
+		if err := ob.checkFillOrKill(order); err != nil {
+			order.Status = OrderStatusRejected
+			return nil, err
+		}
+		me.emitOrderAccepted(ob, order, false)
+		
+		if order.OrderType == OrderTypeMarket {
+			trades, err = me.matchMarketOrder(order, ob)
+		} else {
+			trades, err = me.matchLimitOrder(order, ob)
+		}
+	case OrderTypeStop, OrderTypeStopLimit, OrderTypeTrailingStop:
+		// Stop orders wait in the watchlist until LastPrice crosses StopPrice
+		if err := me.placeStopOrder(order, ob); err != nil {
+			order.Status = OrderStatusRejected
+			return nil, err
+		}
+		me.emitOrderAccepted(ob, order, false)
+		return nil, nil
 	default:
 		return nil, fmt.Errorf("unsupported order type: %s", order.OrderType)
 	}
@@ -471,69 +666,383 @@ func (me *MatchingEngine) PlaceOrder(order *Order) ([]*Trade, error) {
 	}
 	
 	// Update order status
+	updateFillStatus(order)
+	
+	me.emitOrderResult(ob, order)
+	
+	// Trades moved LastPrice: fire any stop orders it crossed
+	if len(trades) > 0 {
+		me.processStopTriggers(ob)
+	}
+	
+	return trades, nil
+}
+
+// updateFillStatus sets FILLED / PARTIALLY_FILLED from the filled quantity.
+// Orders cancelled during matching (self-trade prevention) stay cancelled.
+func updateFillStatus(order *Order) {
+	if order.Status == OrderStatusCancelled {
+		return
+	}
+	
 	if order.IsFilled() {
 		order.Status = OrderStatusFilled
 	} else if order.FilledQuantity.IsPositive() {
 		order.Status = OrderStatusPartiallyFilled
 	}
+}
+
+// CancelOrder cancels an open order of userID. Orders of other users are
+// reported as not found. An empty userID skips the ownership check (admin
+// tools, journals written before the check).
+func (me *MatchingEngine) CancelOrder(orderID string, symbol string, userID string) error {
+	_, err := me.SubmitCancel(orderID, symbol, userID).Wait()
+	return err
+}
+
+// cancelOrder runs on the book's goroutine
+func (me *MatchingEngine) cancelOrder(orderID string, userID string, ob *OrderBook) error {
+	if err := me.checkCancelAccepted(ob); err != nil {
+		return err
+	}
 	
-	// Callback
-	if me.OnOrderUpdate != nil {
-		me.OnOrderUpdate(order)
+	ob.mu.Lock()
+	order, resting := ob.Orders[orderID]
+	if !resting {
+		// Untriggered stop orders live in the watchlist, not the book
+		for _, stop := range ob.StopOrders {
+			if stop.OrderID == orderID {
+				order = stop
+			}
+		}
 	}
+	ob.mu.Unlock()
 	
-	return trades, nil
+	if order == nil || (userID != "" && order.UserID != userID) {
+		return newOrderError(ErrCodeOrderNotFound, "order not found")
+	}
+	
+	if !resting {
+		if _, err := ob.RemoveStopOrder(orderID); err != nil {
+			return err
+		}
+		
+		order.Status = OrderStatusCancelled
+		order.UpdatedAt = ob.now
+		
+		me.emitOrderCancelled(ob, order)
+		
+		return nil
+	}
+	
+	if order.Status != OrderStatusOpen && order.Status != OrderStatusPartiallyFilled {
+		return newOrderError(ErrCodeOrderNotActive, "order cannot be cancelled")
+	}
+	
+	// Remove from order book
+	if err := ob.RemoveOrder(orderID); err != nil {
+		return err
+	}
+	
+	order.Status = OrderStatusCancelled
+	order.UpdatedAt = ob.now
+	
+	me.emitOrderCancelled(ob, order)
+	
+	return nil
+}
+
+// CancelAllOrders cancels the resting and stop orders of userID on symbol, or
+// on every symbol when symbol is "", optionally on one side only. Each book
+// cancels the user's orders in a single step, so none of them trades in
+// between, and still reports every order with its own OrderCancelledEvent.
+// Returns copies of the cancelled orders. When cancelling on every symbol,
+// symbols that accept no cancels are skipped.
+func (me *MatchingEngine) CancelAllOrders(userID string, symbol string, side Side) ([]*Order, error) {
+	if userID == "" {
+		return nil, errors.New("user ID is required")
+	}
+	if side != "" && side != SideBuy && side != SideSell {
+		return nil, fmt.Errorf("invalid side: %s", side)
+	}
+	
+	var books []*OrderBook
+	if symbol != "" {
+		ob, ok := me.GetOrderBook(symbol)
+		if !ok {
+			return nil, errUnknownSymbol(symbol)
+		}
+		books = []*OrderBook{ob}
+	} else {
+		books = me.books()
+		sort.Slice(books, func(i, j int) bool { return books[i].Symbol < books[j].Symbol })
+	}
+	
+	cancelled := make([]*Order, 0)
+	for _, ob := range books {
+		orders, err := me.cancelUserOrdersAt(ob, userID, side, me.clock.Now())
+		if err != nil {
+			if symbol == "" && ErrorCodeOf(err) == ErrCodeSymbolNotTrading {
+				continue
+			}
+			return cancelled, err
+		}
+		cancelled = append(cancelled, orders...)
+	}
+	
+	return cancelled, nil
+}
+
+// cancelUserOrdersAt cancels the user's orders on one book in one command
+func (me *MatchingEngine) cancelUserOrdersAt(ob *OrderBook, userID string, side Side, at time.Time) ([]*Order, error) {
+	var cancelled []*Order
+	err := ob.doAt(at, func() error {
+		entry := &JournalEntry{Type: JournalCancelAll, Symbol: ob.Symbol, UserID: userID, Side: side}
+		if err := me.record(ob, entry); err != nil {
+			return err
+		}
+		if err := me.checkCancelAccepted(ob); err != nil {
+			return err
+		}
+		
+		orders := me.cancelAllOrders(ob, StatusReasonNone, func(order *Order) bool {
+			return order.UserID == userID && (side == "" || order.Side == side)
+		})
+		for _, order := range orders {
+			cancelled = append(cancelled, copyOrder(order))
+		}
+		return nil
+	})
+	
+	return cancelled, err
+}
+
+// AmendOrder changes the price and/or total quantity of a resting limit order
+// in one locked step (FR-003). A zero newPrice or newQuantity keeps the
+// current value. Like CancelOrder, a non-empty userID must own the order.
+//
+// Priority rules:
+//   - Quantity decrease only: order keeps its place in the PriceLevel queue
+//   - Price change or quantity increase: order loses time priority and is
+//     re-matched, so a new price that crosses the book trades immediately
+func (me *MatchingEngine) AmendOrder(orderID string, symbol string, userID string, newPrice, newQuantity decimal.Decimal) ([]*Trade, error) {
+	return me.amendOrderAt(orderID, symbol, userID, newPrice, newQuantity, me.clock.Now())
+}
+
+func (me *MatchingEngine) amendOrderAt(orderID string, symbol string, userID string, newPrice, newQuantity decimal.Decimal, at time.Time) ([]*Trade, error) {
+	if newPrice.IsNegative() || newQuantity.IsNegative() {
+		return nil, errors.New("amended price and quantity must be positive")
+	}
+	
+	ob, ok := me.GetOrderBook(symbol)
+	if !ok {
+		return nil, errUnknownSymbol(symbol)
+	}
+	
+	return ob.submitAt(at, func() ([]*Trade, error) {
+		entry := &JournalEntry{Type: JournalAmend, Symbol: symbol, OrderID: orderID, UserID: userID,
+			Price: newPrice, Quantity: newQuantity}
+		if err := me.record(ob, entry); err != nil {
+			return nil, err
+		}
+		return me.amendOrder(orderID, userID, ob, newPrice, newQuantity)
+	}).Wait()
 }
 
-// CancelOrder cancels an open order
-func (me *MatchingEngine) CancelOrder(orderID string, symbol string) error {
-	ob := me.GetOrCreateOrderBook(symbol)
+// amendOrder runs on the book's goroutine
+func (me *MatchingEngine) amendOrder(orderID string, userID string, ob *OrderBook, newPrice, newQuantity decimal.Decimal) ([]*Trade, error) {
+	if err := me.checkOrderAccepted(ob, nil, ob.now); err != nil {
+		return nil, err
+	}
 	
 	ob.mu.Lock()
 	order, exists := ob.Orders[orderID]
 	ob.mu.Unlock()
 	
-	if !exists {
-		return errors.New("order not found")
+	if !exists || (userID != "" && order.UserID != userID) {
+		return nil, newOrderError(ErrCodeOrderNotFound, "order not found")
 	}
 	
 	if order.Status != OrderStatusOpen && order.Status != OrderStatusPartiallyFilled {
-		return errors.New("order cannot be cancelled")
+		return nil, errors.New("order cannot be amended")
 	}
 	
-	// Remove from order book
+	if newPrice.IsZero() {
+		newPrice = order.Price
+	}
+	if newQuantity.IsZero() {
+		newQuantity = order.Quantity
+	}
+	
+	if newQuantity.LessThanOrEqual(order.FilledQuantity) {
+		return nil, errors.New("amended quantity must exceed filled quantity")
+	}
+	
+	spec := ob.GetSpec()
+	if err := spec.checkPrice(newPrice); err != nil {
+		return nil, err
+	}
+	if err := spec.checkQuantity(newQuantity); err != nil {
+		return nil, err
+	}
+	if err := spec.checkNotional(newPrice, newQuantity); err != nil {
+		return nil, err
+	}
+	if !newPrice.Equal(order.Price) {
+		if err := ob.checkPriceBand(newPrice); err != nil {
+			return nil, err
+		}
+	}
+	if err := ob.checkFixed(newPrice, newQuantity); err != nil {
+		return nil, err
+	}
+	
+	// Quantity decrease at the same price: amend in place, keep priority
+	if newPrice.Equal(order.Price) && newQuantity.LessThanOrEqual(order.Quantity) {
+		ob.mu.Lock()
+		if level := order.level; level != nil {
+			level.Quantity = level.Quantity.Sub(order.Quantity.Sub(newQuantity))
+			ob.touch(order.Side, level)
+		}
+		order.Quantity = newQuantity
+		ob.syncFixed(order.level, order)
+		order.UpdatedAt = ob.now
+		ob.LastUpdateTime = ob.now
+		ob.mu.Unlock()
+		
+		me.emitOrderAccepted(ob, order, true)
+		
+		return nil, nil
+	}
+	
+	// Cancel/replace: leave the queue, then re-enter as a new arrival
 	if err := ob.RemoveOrder(orderID); err != nil {
-		return err
+		return nil, err
 	}
 	
-	order.Status = OrderStatusCancelled
-	order.UpdatedAt = time.Now()
+	order.Price = newPrice
+	order.Quantity = newQuantity
+	ob.syncFixed(nil, order)
+	order.UpdatedAt = ob.now
 	
-	// Callback
-	if me.OnOrderUpdate != nil {
-		me.OnOrderUpdate(order)
+	me.emitOrderAccepted(ob, order, true)
+	
+	trades, err := me.matchLimitOrder(order, ob)
+	if err != nil {
+		return trades, err
 	}
 	
-	return nil
+	updateFillStatus(order)
+	
+	me.emitOrderResult(ob, order)
+	
+	if len(trades) > 0 {
+		me.processStopTriggers(ob)
+	}
+	
+	return trades, nil
 }
 
 // validateOrder validates order parameters
 func (me *MatchingEngine) validateOrder(order *Order) error {
-	if order.Quantity.LessThanOrEqual(decimal.Zero) {
+	if !order.Quantity.IsPositive() {
 		return errors.New("quantity must be positive")
 	}
 	
-	if order.OrderType == OrderTypeLimit && order.Price.LessThanOrEqual(decimal.Zero) {
+	if order.OrderType == OrderTypeLimit && !order.Price.IsPositive() {
 		return errors.New("limit order must have positive price")
 	}
 	
+	isStop := order.OrderType == OrderTypeStop || order.OrderType == OrderTypeStopLimit
+	if isStop && !order.StopPrice.IsPositive() {
+		return errors.New("stop order must have positive stop price")
+	}
+	
+	if order.OrderType == OrderTypeStopLimit && !order.Price.IsPositive() {
+		return errors.New("stop-limit order must have positive price")
+	}
+	
+	if order.OrderType == OrderTypeTrailingStop {
+		return validateTrailingOffset(order)
+	}
+	
 	return nil
 }
 
+// checkFillOrKill rejects a FOK order the book cannot fill completely.
+// Checked before the order is accepted, so a kill leaves the book untouched.
+func (ob *OrderBook) checkFillOrKill(order *Order) error {
+	if order.TimeInForce != TimeInForceFOK {
+		return nil
+	}
+	
+	// Market and stop orders trade up to the price protection limit
+	limit := order.Price
+	if order.OrderType != OrderTypeLimit && order.OrderType != OrderTypeStopLimit {
+		limit = ob.marketProtectionPrice(order.Side)
+	}
+	
+	if !ob.canFillCompletely(order, limit) {
+		return newOrderError(ErrCodeFillOrKill, "FOK order could not be filled completely")
+	}
+	return nil
+}
+
+// canFillCompletely checks, without modifying the book, whether resting
+// liquidity the order may trade against covers its remaining quantity.
+// limit is the worst price the order may trade at (zero for no limit).
+// Used to make Fill-or-Kill all-or-nothing before any fill is applied.
+func (ob *OrderBook) canFillCompletely(order *Order, limit decimal.Decimal) bool {
+	queue := ob.sideQueue(oppositeSide(order.Side))
+	
+	required := order.RemainingQuantity()
+	available := decimal.Zero
+	filled := false
+	
+	// Levels are sorted best first: stop at the first one past the limit
+	queue.Each(func(level *PriceLevel) bool {
+		if !limit.IsZero() && !withinLimit(order.Side, level.Price, limit) {
+			return false
+		}
+		
+		if order.STPMode == STPModeNone {
+			available = available.Add(level.Quantity)
+		} else {
+			// Own orders never fill this order. Only CANCEL_OLDEST trades
+			// past them; any other mode may stop or shrink the order, so
+			// a FOK that would meet one is conservatively killed.
+			for resting := level.Front(); resting != nil; resting = resting.next {
+				if !isSelfTrade(order, resting) {
+					available = available.Add(resting.RemainingQuantity())
+				} else if order.STPMode != STPModeCancelOldest {
+					return false
+				}
+			}
+		}
+		
+		filled = available.GreaterThanOrEqual(required)
+		return !filled
+	})
+	
+	return filled
+}
+
+// oppositeSide returns the side an order of side trades against
+func oppositeSide(side Side) Side {
+	if side == SideBuy {
+		return SideSell
+	}
+	return SideBuy
+}
+
 // matchMarketOrder matches a market order
 func (me *MatchingEngine) matchMarketOrder(order *Order, ob *OrderBook) ([]*Trade, error) {
+	// Price protection: never walk the book past the price band (RMR-004)
+	protectionPrice := ob.marketProtectionPrice(order.Side)
+	protectionTicks := ob.limitTicks(order.Side, protectionPrice)
+	
 	trades := make([]*Trade, 0)
-	remaining := order.Quantity
+	remaining := ob.remainingOf(order)
 	
 	// Determine which side of the book to match against
 	var queue *PriceQueue
@@ -547,63 +1056,65 @@ func (me *MatchingEngine) matchMarketOrder(order *Order, ob *OrderBook) ([]*Trad
 	for remaining.IsPositive() && queue.Len() > 0 {
 		// Get best price level
 		level := queue.Peek()
-		if level == nil || level.IsEmpty() {
+		if level == nil {
+			break
+		}
+		
+		if !protectionPrice.IsZero() && !ob.levelWithin(order.Side, level, protectionPrice, protectionTicks) {
+			order.StatusReason = StatusReasonPriceProtection
 			break
 		}
 		
 		// Match against orders at this level (FIFO)
-		for len(level.Orders) > 0 && remaining.IsPositive() {
-			matchOrder := level.Orders[0]
+		for !level.IsEmpty() && remaining.IsPositive() {
+			matchOrder := level.Front()
+			
+			// Self-trade prevention (RMR-005)
+			if isSelfTrade(order, matchOrder) {
+				remaining = me.preventSelfTrade(order, matchOrder, level, ob)
+				continue
+			}
 			
-			// Calculate fill quantity
-			fillQty := decimal.Min(remaining, matchOrder.RemainingQuantity())
+			// Fill and update quantities
+			fill, rest, makerFilled := ob.applyFill(order, matchOrder, level, remaining)
+			remaining = rest
 			
 			// Create trade
-			trade := me.createTrade(order, matchOrder, level.Price, fillQty, false)
+			trade := me.createTrade(ob, order, matchOrder, level, fill)
 			trades = append(trades, trade)
 			
-			// Update filled quantities
-			order.FilledQuantity = order.FilledQuantity.Add(fillQty)
-			matchOrder.FilledQuantity = matchOrder.FilledQuantity.Add(fillQty)
-			remaining = remaining.Sub(fillQty)
-			
 			// Update match order status
-			if matchOrder.IsFilled() {
+			if makerFilled {
 				matchOrder.Status = OrderStatusFilled
-				level.RemoveOrder(matchOrder.OrderID)
+				level.RemoveOrder(matchOrder)
 				ob.Orders[matchOrder.OrderID] = nil
 				delete(ob.Orders, matchOrder.OrderID)
 				
-				if me.OnOrderUpdate != nil {
-					me.OnOrderUpdate(matchOrder)
-				}
+				me.emitOrderFilled(ob, matchOrder)
 			} else {
 				matchOrder.Status = OrderStatusPartiallyFilled
-				level.Quantity = level.Quantity.Sub(fillQty)
 				
-				if me.OnOrderUpdate != nil {
-					me.OnOrderUpdate(matchOrder)
-				}
+				me.emitOrderFilled(ob, matchOrder)
 			}
 			
 			// Callback for trade
-			if me.OnTrade != nil {
-				me.OnTrade(trade)
+			me.emitTrade(ob, trade)
+			ob.trailStops(trade.Price)
+			
+			// Circuit breaker (RMR-003): matching stops at the trade that trips it
+			if me.recordTrade(ob, trade) {
+				remaining = cancelAtHalt(ob, order, remaining)
 			}
 		}
 		
 		// Remove empty price level
 		if level.IsEmpty() {
-			heap.Pop(queue)
-			delete(ob.PriceLevels, level.Price.String())
+			ob.removeLevel(queue, level)
+		} else {
+			ob.storeFixed(level)
 		}
 	}
-	
-	// Check if FOK and not filled
-	if order.TimeInForce == TimeInForceFOK && remaining.IsPositive() {
-		// Rollback trades (in production, this would be handled differently)
-		return nil, errors.New("FOK order could not be filled completely")
-	}
+	ob.storeFixed(nil, order)
 	
 	// Update last price
 	if len(trades) > 0 {
@@ -616,73 +1127,83 @@ func (me *MatchingEngine) matchMarketOrder(order *Order, ob *OrderBook) ([]*Trad
 // matchLimitOrder matches a limit order
 func (me *MatchingEngine) matchLimitOrder(order *Order, ob *OrderBook) ([]*Trade, error) {
 	trades := make([]*Trade, 0)
-	remaining := order.Quantity
+	remaining := ob.remainingOf(order)
 	
 	// Determine if order can be matched
 	var queue *PriceQueue
-	var canMatch func(decimal.Decimal) bool
+	var canMatch func(*PriceLevel) bool
 	
 	if order.Side == SideBuy {
 		queue = ob.Asks
-		canMatch = func(price decimal.Decimal) bool {
-			return order.Price.GreaterThanOrEqual(price)
+		canMatch = func(level *PriceLevel) bool {
+			if ob.fx != nil {
+				return order.fxPrice >= level.fxPrice
+			}
+			return order.Price.GreaterThanOrEqual(level.Price)
 		}
 	} else {
 		queue = ob.Bids
-		canMatch = func(price decimal.Decimal) bool {
-			return order.Price.LessThanOrEqual(price)
+		canMatch = func(level *PriceLevel) bool {
+			if ob.fx != nil {
+				return order.fxPrice <= level.fxPrice
+			}
+			return order.Price.LessThanOrEqual(level.Price)
 		}
 	}
 	
 	// Try to match
 	for remaining.IsPositive() && queue.Len() > 0 {
 		level := queue.Peek()
-		if level == nil || !canMatch(level.Price) {
+		if level == nil || !canMatch(level) {
 			break
 		}
 		
 		// Match against orders at this level
-		for len(level.Orders) > 0 && remaining.IsPositive() {
-			matchOrder := level.Orders[0]
+		for !level.IsEmpty() && remaining.IsPositive() {
+			matchOrder := level.Front()
 			
-			fillQty := decimal.Min(remaining, matchOrder.RemainingQuantity())
+			// Self-trade prevention (RMR-005)
+			if isSelfTrade(order, matchOrder) {
+				remaining = me.preventSelfTrade(order, matchOrder, level, ob)
+				continue
+			}
 			
-			// Create trade (incoming limit order is taker)
-			trade := me.createTrade(order, matchOrder, level.Price, fillQty, false)
-			trades = append(trades, trade)
+			fill, rest, makerFilled := ob.applyFill(order, matchOrder, level, remaining)
+			remaining = rest
 			
-			order.FilledQuantity = order.FilledQuantity.Add(fillQty)
-			matchOrder.FilledQuantity = matchOrder.FilledQuantity.Add(fillQty)
-			remaining = remaining.Sub(fillQty)
+			// Create trade (incoming limit order is taker, resting order is maker)
+			trade := me.createTrade(ob, order, matchOrder, level, fill)
+			trades = append(trades, trade)
 			
 			// Update match order
-			if matchOrder.IsFilled() {
+			if makerFilled {
 				matchOrder.Status = OrderStatusFilled
-				level.RemoveOrder(matchOrder.OrderID)
+				level.RemoveOrder(matchOrder)
 				delete(ob.Orders, matchOrder.OrderID)
 				
-				if me.OnOrderUpdate != nil {
-					me.OnOrderUpdate(matchOrder)
-				}
+				me.emitOrderFilled(ob, matchOrder)
 			} else {
 				matchOrder.Status = OrderStatusPartiallyFilled
-				level.Quantity = level.Quantity.Sub(fillQty)
 				
-				if me.OnOrderUpdate != nil {
-					me.OnOrderUpdate(matchOrder)
-				}
+				me.emitOrderFilled(ob, matchOrder)
 			}
 			
-			if me.OnTrade != nil {
-				me.OnTrade(trade)
+			me.emitTrade(ob, trade)
+			ob.trailStops(trade.Price)
+			
+			// Circuit breaker (RMR-003): matching stops at the trade that trips it
+			if me.recordTrade(ob, trade) {
+				remaining = cancelAtHalt(ob, order, remaining)
 			}
 		}
 		
 		if level.IsEmpty() {
-			heap.Pop(queue)
-			delete(ob.PriceLevels, level.Price.String())
+			ob.removeLevel(queue, level)
+		} else {
+			ob.storeFixed(level)
 		}
 	}
+	ob.storeFixed(nil, order)
 	
 	// Add remaining quantity to order book (maker)
 	if remaining.IsPositive() {
@@ -691,11 +1212,6 @@ func (me *MatchingEngine) matchLimitOrder(order *Order, ob *OrderBook) ([]*Trade
 			return trades, nil
 		}
 		
-		if order.TimeInForce == TimeInForceFOK && remaining.Equal(order.Quantity) {
-			// FOK: Must fill completely
-			return nil, errors.New("FOK order could not be filled")
-		}
-		
 		// Add to order book
 		if err := ob.AddOrder(order); err != nil {
 			return trades, err
@@ -710,88 +1226,116 @@ func (me *MatchingEngine) matchLimitOrder(order *Order, ob *OrderBook) ([]*Trade
 	return trades, nil
 }
 
-// createTrade creates a trade record
-func (me *MatchingEngine) createTrade(incomingOrder, matchOrder *Order, price, quantity decimal.Decimal, isMaker bool) *Trade {
+// createTrade creates a trade record.
+//
+// The incoming (aggressor) order is always the taker and the resting order
+// it matched is always the maker; fees follow from those roles.
+func (me *MatchingEngine) createTrade(ob *OrderBook, takerOrder, makerOrder *Order, level *PriceLevel, fill matchQuantity) *Trade {
 	trade := &Trade{
-		TradeID:      uuid.New().String(),
-		Symbol:       incomingOrder.Symbol,
-		Price:        price,
-		Quantity:     quantity,
-		ExecutedAt:   time.Now(),
+		TradeID:       me.tradeID(ob, takerOrder, fill),
+		Symbol:        takerOrder.Symbol,
+		Price:         level.Price,
+		Quantity:      ob.quantity(fill),
+		TakerOrderID:  takerOrder.OrderID,
+		MakerOrderID:  makerOrder.OrderID,
+		AggressorSide: takerOrder.Side,
+		ExecutedAt:    ob.now,
 	}
 	
 	// Determine buyer and seller
-	if incomingOrder.Side == SideBuy {
-		trade.BuyerOrderID = incomingOrder.OrderID
-		trade.BuyerUserID = incomingOrder.UserID
-		trade.SellerOrderID = matchOrder.OrderID
-		trade.SellerUserID = matchOrder.UserID
-		trade.IsBuyerMaker = isMaker
+	if takerOrder.Side == SideBuy {
+		trade.BuyerOrderID = takerOrder.OrderID
+		trade.BuyerUserID = takerOrder.UserID
+		trade.SellerOrderID = makerOrder.OrderID
+		trade.SellerUserID = makerOrder.UserID
 	} else {
-		trade.BuyerOrderID = matchOrder.OrderID
-		trade.BuyerUserID = matchOrder.UserID
-		trade.SellerOrderID = incomingOrder.OrderID
-		trade.SellerUserID = incomingOrder.UserID
-		trade.IsBuyerMaker = !isMaker
+		trade.BuyerOrderID = makerOrder.OrderID
+		trade.BuyerUserID = makerOrder.UserID
+		trade.SellerOrderID = takerOrder.OrderID
+		trade.SellerUserID = takerOrder.UserID
 	}
+	trade.IsBuyerMaker = trade.AggressorSide == SideSell
 	
-	// Calculate fees
-	tradeValue := price.Mul(quantity)
+	// Calculate fees: maker fee for the resting side, taker fee for the aggressor
+	makerFee, takerFee := ob.fees(level, fill, me.MakerFee, me.TakerFee)
 	if trade.IsBuyerMaker {
-		trade.BuyerFee = tradeValue.Mul(me.MakerFee)
-		trade.SellerFee = tradeValue.Mul(me.TakerFee)
+		trade.BuyerFee, trade.SellerFee = makerFee, takerFee
 	} else {
-		trade.BuyerFee = tradeValue.Mul(me.TakerFee)
-		trade.SellerFee = tradeValue.Mul(me.MakerFee)
+		trade.BuyerFee, trade.SellerFee = takerFee, makerFee
 	}
 	
 	return trade
 }
 
+// tradeID returns the ID of the trade recording taker's fill (already
+// applied to taker)
+func (me *MatchingEngine) tradeID(ob *OrderBook, taker *Order, fill matchQuantity) string {
+	if me.tradeIDs != nil {
+		return me.tradeIDs.NewID()
+	}
+	
+	if fill.fixed {
+		return fillTradeID(taker.OrderID, ob.fx.Quantity(taker.fxFilled-fill.lots))
+	}
+	return fillTradeID(taker.OrderID, taker.FilledQuantity.Sub(fill.value))
+}
+
 // ============================================================================
 // STATISTICS & MONITORING
 // ============================================================================
 
-// GetOrderBookSnapshot returns current order book state
+// GetOrderBookSnapshot returns current order book state, or nil for an
+// unknown symbol
 func (me *MatchingEngine) GetOrderBookSnapshot(symbol string, depth int) map[string]interface{} {
-	ob := me.GetOrCreateOrderBook(symbol)
-	bids, asks := ob.GetDepth(depth)
+	var snapshot map[string]interface{}
+	me.ReadOrderBook(symbol, func(ob *OrderBook) {
+		bids, asks := ob.GetDepth(depth)
+		
+		snapshot = map[string]interface{}{
+			"symbol":      symbol,
+			"bids":        bids,
+			"asks":        asks,
+			"last_price":  ob.LastPrice.String(),
+			"best_bid":    ob.GetBestBid().String(),
+			"best_ask":    ob.GetBestAsk().String(),
+			"timestamp":   ob.LastUpdateTime.Format(time.RFC3339),
+			"last_update_id": ob.seq, // Per-symbol sequence of the latest event
+		}
+	})
 	
-	return map[string]interface{}{
-		"symbol":      symbol,
-		"bids":        bids,
-		"asks":        asks,
-		"last_price":  ob.LastPrice.String(),
-		"best_bid":    ob.GetBestBid().String(),
-		"best_ask":    ob.GetBestAsk().String(),
-		"timestamp":   ob.LastUpdateTime.Format(time.RFC3339),
-	}
+	return snapshot
 }
 
 // GetStatistics returns matching engine statistics
 func (me *MatchingEngine) GetStatistics() map[string]interface{} {
 	me.mu.RLock()
-	defer me.mu.RUnlock()
-	
-	stats := map[string]interface{}{
-		"total_symbols": len(me.OrderBooks),
-		"symbols":       make(map[string]interface{}),
+	symbols := make([]string, 0, len(me.OrderBooks))
+	for symbol := range me.OrderBooks {
+		symbols = append(symbols, symbol)
 	}
+	me.mu.RUnlock()
 	
-	for symbol, ob := range me.OrderBooks {
-		ob.mu.RLock()
-		stats["symbols"].(map[string]interface{})[symbol] = map[string]interface{}{
-			"total_orders":  len(ob.Orders),
-			"bid_levels":    ob.Bids.Len(),
-			"ask_levels":    ob.Asks.Len(),
-			"last_price":    ob.LastPrice.String(),
-			"best_bid":      ob.GetBestBid().String(),
-			"best_ask":      ob.GetBestAsk().String(),
-		}
-		ob.mu.RUnlock()
+	perSymbol := make(map[string]interface{})
+	for _, symbol := range symbols {
+		me.ReadOrderBook(symbol, func(ob *OrderBook) {
+			perSymbol[symbol] = map[string]interface{}{
+				"total_orders":  len(ob.Orders),
+				"bid_levels":    ob.Bids.Len(),
+				"ask_levels":    ob.Asks.Len(),
+				"stop_orders":   len(ob.StopOrders),
+				"last_price":    ob.LastPrice.String(),
+				"best_bid":      ob.GetBestBid().String(),
+				"best_ask":      ob.GetBestAsk().String(),
+				"sequence":      ob.seq,
+			}
+		})
 	}
 	
-	return stats
+	return map[string]interface{}{
+		"total_symbols":   len(symbols),
+		"global_sequence": me.GlobalSequence(),
+		"symbols":         perSymbol,
+	}
 }
 
 // ============================================================================
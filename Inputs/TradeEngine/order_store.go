@@ -0,0 +1,231 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - ORDER STORE
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Order Query, FR-004)
+// Description: Latest state and fills of every order, including orders that
+//              left the book (FILLED, CANCELLED, REJECTED), fed by the
+//              engine's events and queried by user, symbol and status with
+//              cursor pagination
+// ============================================================================
+
+package matching
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Order query page sizes
+const (
+	DefaultOrderQueryLimit = 50
+	MaxOrderQueryLimit     = 100
+)
+
+// ErrInvalidCursor rejects a cursor that no OrderStore or TradeStore page
+// returned
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// StoredOrder is an order with the trades it took part in
+type StoredOrder struct {
+	Order *Order
+	Fills []*Trade // Execution order
+}
+
+// OrderQuery selects the orders of one user. Zero fields do not filter.
+type OrderQuery struct {
+	UserID    string
+	Symbol    string
+	Status    OrderStatus
+	OrderType OrderType
+	From, To  time.Time // CreatedAt range, To exclusive
+	Cursor    string    // NextCursor of the previous page; "" for the first
+	Limit     int       // 0 = DefaultOrderQueryLimit
+}
+
+// OrderPage is one page of a query, newest order first
+type OrderPage struct {
+	Orders     []StoredOrder
+	NextCursor string // "" on the last page
+}
+
+type storedOrder struct {
+	order *Order // Copy from the latest event: replaced, never modified
+	fills []*Trade
+	pos   uint64 // Arrival position, the pagination key
+}
+
+// OrderStore keeps every order the engine has published, so orders can be
+// queried after leaving the book. Orders stay in memory until the database
+// takes over history.
+type OrderStore struct {
+	sub *Subscription
+
+	mu     sync.RWMutex
+	orders map[string]*storedOrder
+	byUser map[string][]*storedOrder // Arrival order
+	next   uint64
+}
+
+// NewOrderStore subscribes a store to the engine's events and seeds it with
+// the orders already resting (e.g. restored from a snapshot). Created before
+// RecoverFromJournal, it also keeps the orders the journal replay publishes.
+func NewOrderStore(me *MatchingEngine) (*OrderStore, error) {
+	s := &OrderStore{
+		orders: make(map[string]*storedOrder),
+		byUser: make(map[string][]*storedOrder),
+	}
+
+	sub, err := me.Subscribe(s, SubscriptionConfig{Backpressure: BackpressureBlock})
+	if err != nil {
+		return nil, err
+	}
+	s.sub = sub
+
+	var resting []*Order
+	for _, ob := range me.books() {
+		ob.do(func() error {
+			for _, order := range ob.Orders {
+				resting = append(resting, copyOrder(order))
+			}
+			for _, order := range ob.StopOrders {
+				resting = append(resting, copyOrder(order))
+			}
+			return nil
+		})
+	}
+	sort.Slice(resting, func(i, j int) bool {
+		if !resting[i].CreatedAt.Equal(resting[j].CreatedAt) {
+			return resting[i].CreatedAt.Before(resting[j].CreatedAt)
+		}
+		return resting[i].GlobalSequence < resting[j].GlobalSequence
+	})
+
+	s.mu.Lock()
+	for _, order := range resting {
+		s.update(order)
+	}
+	s.mu.Unlock()
+
+	return s, nil
+}
+
+// HandleEvent records order and trade events. Runs on the subscription's
+// goroutine.
+func (s *OrderStore) HandleEvent(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if trade, ok := event.(*TradeEvent); ok {
+		for _, orderID := range []string{trade.Trade.BuyerOrderID, trade.Trade.SellerOrderID} {
+			if so, ok := s.orders[orderID]; ok {
+				so.fills = append(so.fills, trade.Trade)
+			}
+		}
+		return
+	}
+
+	if order := OrderOf(event); order != nil {
+		s.update(order)
+	}
+}
+
+// update stores order unless a later state is already stored (seeded orders
+// may be newer than events still queued). Called under mu.
+func (s *OrderStore) update(order *Order) {
+	if so, ok := s.orders[order.OrderID]; ok {
+		if order.GlobalSequence >= so.order.GlobalSequence {
+			so.order = order
+		}
+		return
+	}
+
+	s.next++
+	so := &storedOrder{order: order, pos: s.next}
+	s.orders[order.OrderID] = so
+	s.byUser[order.UserID] = append(s.byUser[order.UserID], so)
+}
+
+// Get returns an order with its fills. Orders published before the call are
+// included.
+func (s *OrderStore) Get(orderID string) (StoredOrder, bool) {
+	s.sub.Flush()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	so, ok := s.orders[orderID]
+	if !ok {
+		return StoredOrder{}, false
+	}
+	return so.stored(), true
+}
+
+// Query returns a page of a user's orders, newest first. Orders published
+// before the call are included.
+func (s *OrderStore) Query(q OrderQuery) (OrderPage, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultOrderQueryLimit
+	}
+	if limit > MaxOrderQueryLimit {
+		limit = MaxOrderQueryLimit
+	}
+
+	s.sub.Flush()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	orders := s.byUser[q.UserID]
+
+	// Start below the cursor's position
+	end := len(orders)
+	if q.Cursor != "" {
+		pos, err := strconv.ParseUint(q.Cursor, 10, 64)
+		if err != nil {
+			return OrderPage{}, ErrInvalidCursor
+		}
+		end = sort.Search(len(orders), func(i int) bool { return orders[i].pos >= pos })
+	}
+
+	page := OrderPage{Orders: make([]StoredOrder, 0)}
+	var last uint64
+	for i := end - 1; i >= 0; i-- {
+		so := orders[i]
+		if !q.matches(so.order) {
+			continue
+		}
+		if len(page.Orders) == limit {
+			page.NextCursor = strconv.FormatUint(last, 10)
+			break
+		}
+		page.Orders = append(page.Orders, so.stored())
+		last = so.pos
+	}
+	return page, nil
+}
+
+func (q *OrderQuery) matches(order *Order) bool {
+	return (q.Symbol == "" || order.Symbol == q.Symbol) &&
+		(q.Status == "" || order.Status == q.Status) &&
+		(q.OrderType == "" || order.OrderType == q.OrderType) &&
+		(q.From.IsZero() || !order.CreatedAt.Before(q.From)) &&
+		(q.To.IsZero() || order.CreatedAt.Before(q.To))
+}
+
+// stored returns a copy safe to read outside mu. Called under mu.
+func (so *storedOrder) stored() StoredOrder {
+	return StoredOrder{
+		Order: so.order,
+		Fills: append([]*Trade(nil), so.fills...),
+	}
+}
+
+// Close unsubscribes the store
+func (s *OrderStore) Close() {
+	s.sub.Close()
+}
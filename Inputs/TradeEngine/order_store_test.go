@@ -0,0 +1,180 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - ORDER STORE TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOrderStore(t *testing.T, me *MatchingEngine) *OrderStore {
+	s, err := NewOrderStore(me)
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// userOrder returns a test order of user on symbol
+func userOrder(user, symbol string, side Side, quantity, price string) *Order {
+	order := newTestOrder(side, OrderTypeLimit, quantity, price)
+	order.UserID = user
+	order.Symbol = symbol
+	return order
+}
+
+func TestOrderStore_KeepsTerminalOrders(t *testing.T) {
+	me := newTestEngine()
+	s := newTestOrderStore(t, me)
+
+	ask := userOrder("alice", "BTC/USDT", SideSell, "1.0", "50000")
+	cancelled := userOrder("alice", "BTC/USDT", SideSell, "1.0", "51000")
+	bid := userOrder("bob", "BTC/USDT", SideBuy, "1.0", "50000")
+	fok := userOrder("bob", "BTC/USDT", SideBuy, "1.0", "52000")
+	fok.TimeInForce = TimeInForceFOK
+
+	for _, order := range []*Order{ask, cancelled, bid} {
+		_, err := me.PlaceOrder(order)
+		require.NoError(t, err)
+	}
+	require.NoError(t, me.CancelOrder(cancelled.OrderID, "BTC/USDT", cancelled.UserID))
+	_, err := me.PlaceOrder(fok)
+	require.Error(t, err)
+
+	// None of them rests in the book any more
+	ob, _ := me.GetOrderBook("BTC/USDT")
+	assert.Equal(t, 0, len(ob.Orders))
+
+	for order, status := range map[*Order]OrderStatus{
+		ask:       OrderStatusFilled,
+		cancelled: OrderStatusCancelled,
+		bid:       OrderStatusFilled,
+		fok:       OrderStatusRejected,
+	} {
+		stored, ok := s.Get(order.OrderID)
+		require.True(t, ok)
+		assert.Equal(t, status, stored.Order.Status)
+		assert.Equal(t, order.UserID, stored.Order.UserID)
+	}
+
+	// The trade is a fill of both sides
+	stored, _ := s.Get(ask.OrderID)
+	require.Equal(t, 1, len(stored.Fills))
+	assert.Equal(t, bid.OrderID, stored.Fills[0].BuyerOrderID)
+	stored, _ = s.Get(bid.OrderID)
+	assert.Equal(t, 1, len(stored.Fills))
+	stored, _ = s.Get(cancelled.OrderID)
+	assert.Empty(t, stored.Fills)
+
+	_, ok := s.Get("no-such-order")
+	assert.False(t, ok)
+}
+
+func TestOrderStore_Query(t *testing.T) {
+	me, clock := newTestClockEngine()
+	s := newTestOrderStore(t, me)
+
+	// alice: BTC 1, ETH 2, BTC 3, ETH 4, BTC 5 (newest); one from bob
+	var alice []*Order
+	for i, symbol := range []string{"BTC/USDT", "ETH/USDT", "BTC/USDT", "ETH/USDT", "BTC/USDT"} {
+		clock.Advance(time.Second)
+		order := userOrder("alice", symbol, SideBuy, "1.0", "49000")
+		_, err := me.PlaceOrder(order)
+		require.NoError(t, err)
+		alice = append(alice, order)
+		if i == 1 {
+			_, err := me.PlaceOrder(userOrder("bob", symbol, SideBuy, "1.0", "49000"))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, me.CancelOrder(alice[2].OrderID, "BTC/USDT", alice[2].UserID))
+
+	ids := func(page OrderPage) []string {
+		ids := make([]string, 0)
+		for _, stored := range page.Orders {
+			ids = append(ids, stored.Order.OrderID)
+		}
+		return ids
+	}
+
+	// Newest first, paged with the cursor
+	page, err := s.Query(OrderQuery{UserID: "alice", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{alice[4].OrderID, alice[3].OrderID}, ids(page))
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = s.Query(OrderQuery{UserID: "alice", Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{alice[2].OrderID, alice[1].OrderID}, ids(page))
+
+	page, err = s.Query(OrderQuery{UserID: "alice", Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{alice[0].OrderID}, ids(page))
+	assert.Empty(t, page.NextCursor)
+
+	// Filters
+	page, err = s.Query(OrderQuery{UserID: "alice", Symbol: "BTC/USDT", Status: OrderStatusOpen})
+	require.NoError(t, err)
+	assert.Equal(t, []string{alice[4].OrderID, alice[0].OrderID}, ids(page))
+
+	page, err = s.Query(OrderQuery{UserID: "alice", Status: OrderStatusCancelled})
+	require.NoError(t, err)
+	assert.Equal(t, []string{alice[2].OrderID}, ids(page))
+
+	page, err = s.Query(OrderQuery{UserID: "alice", From: testEpoch.Add(2 * time.Second), To: testEpoch.Add(4 * time.Second)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{alice[2].OrderID, alice[1].OrderID}, ids(page))
+
+	// A filtered page continues below its last order, not below skipped ones
+	page, err = s.Query(OrderQuery{UserID: "alice", Symbol: "ETH/USDT", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{alice[3].OrderID}, ids(page))
+	page, err = s.Query(OrderQuery{UserID: "alice", Symbol: "ETH/USDT", Limit: 1, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{alice[1].OrderID}, ids(page))
+
+	page, err = s.Query(OrderQuery{UserID: "carol"})
+	require.NoError(t, err)
+	assert.Empty(t, page.Orders)
+
+	_, err = s.Query(OrderQuery{UserID: "alice", Cursor: "abc"})
+	assert.Equal(t, ErrInvalidCursor, err)
+}
+
+func TestOrderStore_SeedsRestoredOrders(t *testing.T) {
+	me := newTestEngine()
+	resting := userOrder("alice", "BTC/USDT", SideBuy, "1.0", "49000")
+	_, err := me.PlaceOrder(resting)
+	require.NoError(t, err)
+	stop := newTestStopOrder(SideSell, "1.0", "48000")
+	stop.UserID = "alice"
+	tradeAt(t, me, "50000")
+	_, err = me.PlaceOrder(stop)
+	require.NoError(t, err)
+
+	snapshot, err := me.TakeSnapshot()
+	require.NoError(t, err)
+	me.Close()
+
+	// A restored engine publishes nothing for the orders it already holds
+	restored := newTestEngine()
+	defer restored.Close()
+	require.NoError(t, restored.RestoreSnapshot(snapshot))
+	s := newTestOrderStore(t, restored)
+
+	page, err := s.Query(OrderQuery{UserID: "alice"})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(page.Orders))
+	assert.Equal(t, stop.OrderID, page.Orders[0].Order.OrderID)
+	assert.Equal(t, resting.OrderID, page.Orders[1].Order.OrderID)
+
+	// Later events update the seeded orders
+	require.NoError(t, restored.CancelOrder(resting.OrderID, "BTC/USDT", resting.UserID))
+	stored, ok := s.Get(resting.OrderID)
+	require.True(t, ok)
+	assert.Equal(t, OrderStatusCancelled, stored.Order.Status)
+}
@@ -0,0 +1,611 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - ORDER API
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Trade Engine Server (REST)
+// Description: Order endpoints of trade-engine-api-spec.yaml, with errors
+//              reported as RFC 7807 problem details
+// ============================================================================
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mytrader/trade-engine/internal/matching"
+	"github.com/shopspring/decimal"
+)
+
+// userIDHeader carries the authenticated user. Set by the API gateway once
+// it has validated the JWT (temporary until the engine checks tokens itself).
+const userIDHeader = "X-User-ID"
+
+// problemTypeBase prefixes the problem type URIs of the API spec
+const problemTypeBase = "https://api.mytrader.com/errors/"
+
+// decimalPattern is the decimal string format of the API spec
+var decimalPattern = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// ============================================================================
+// PROBLEM DETAILS (RFC 7807)
+// ============================================================================
+
+type problem struct {
+	Type     string             `json:"type"`
+	Title    string             `json:"title"`
+	Status   int                `json:"status"`
+	Detail   string             `json:"detail,omitempty"`
+	Instance string             `json:"instance,omitempty"`
+	Code     matching.ErrorCode `json:"code,omitempty"` // Engine rejection code
+}
+
+// writeProblem aborts the request with a problem response. slug names the
+// problem type, e.g. "invalid-request".
+func writeProblem(c *gin.Context, status int, slug, title, detail string) {
+	abortWithProblem(c, problem{
+		Type:   problemTypeBase + slug,
+		Title:  title,
+		Status: status,
+		Detail: detail,
+	})
+}
+
+func abortWithProblem(c *gin.Context, p problem) {
+	p.Instance = c.Request.URL.Path
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// rejectionProblems maps engine rejection codes to HTTP statuses and titles.
+// Trading rule violations are the client's fault; the others depend on the
+// market state at the time of the order.
+var rejectionProblems = map[matching.ErrorCode]struct {
+	status int
+	title  string
+}{
+	matching.ErrCodeInvalidTickSize:  {http.StatusBadRequest, "Invalid Tick Size"},
+	matching.ErrCodeInvalidStepSize:  {http.StatusBadRequest, "Invalid Step Size"},
+	matching.ErrCodeQuantityTooSmall: {http.StatusBadRequest, "Quantity Too Small"},
+	matching.ErrCodeQuantityTooLarge: {http.StatusBadRequest, "Quantity Too Large"},
+	matching.ErrCodeNotionalTooSmall: {http.StatusBadRequest, "Notional Too Small"},
+	matching.ErrCodeUnknownSymbol:    {http.StatusBadRequest, "Unknown Symbol"},
+	matching.ErrCodePriceOutOfBand:   {http.StatusConflict, "Price Out Of Band"},
+	matching.ErrCodeSymbolHalted:     {http.StatusConflict, "Symbol Halted"},
+	matching.ErrCodeSymbolNotTrading: {http.StatusConflict, "Symbol Not Trading"},
+	matching.ErrCodeFillOrKill:       {http.StatusConflict, "Fill Or Kill Not Filled"},
+	matching.ErrCodeOrderNotFound:    {http.StatusNotFound, "Not Found"},
+	matching.ErrCodeOrderNotActive:   {http.StatusConflict, "Order Not Active"},
+}
+
+// writeEngineError reports an error returned by the matching engine
+func writeEngineError(c *gin.Context, err error) {
+	code := matching.ErrorCodeOf(err)
+	if p, ok := rejectionProblems[code]; ok {
+		abortWithProblem(c, problem{
+			Type:   problemTypeBase + strings.ToLower(strings.ReplaceAll(string(code), "_", "-")),
+			Title:  p.title,
+			Status: p.status,
+			Detail: err.Error(),
+			Code:   code,
+		})
+		return
+	}
+
+	if errors.Is(err, matching.ErrBookClosed) {
+		writeProblem(c, http.StatusServiceUnavailable, "service-unavailable", "Service Unavailable", err.Error())
+		return
+	}
+
+	// Plain engine errors are order validation failures
+	writeProblem(c, http.StatusBadRequest, "invalid-order", "Invalid Order", err.Error())
+}
+
+// requireUser returns the authenticated user, or writes a 401 problem
+func requireUser(c *gin.Context) (string, bool) {
+	userID := c.GetHeader(userIDHeader)
+	if userID == "" {
+		writeProblem(c, http.StatusUnauthorized, "unauthorized", "Unauthorized", "missing "+userIDHeader+" header")
+		return "", false
+	}
+	return userID, true
+}
+
+// ============================================================================
+// REQUESTS & RESPONSES
+// ============================================================================
+
+type createOrderRequest struct {
+	ClientOrderID   string `json:"client_order_id"`
+	Symbol          string `json:"symbol" binding:"required"`
+	Side            string `json:"side" binding:"required"`
+	OrderType       string `json:"order_type" binding:"required"`
+	Quantity        string `json:"quantity" binding:"required"`
+	Price           string `json:"price"`
+	StopPrice       string `json:"stop_price"`
+	TrailingOffset  string `json:"trailing_offset"`
+	TrailingPercent bool   `json:"trailing_percent"`
+	TimeInForce     string `json:"time_in_force"`
+	STPMode         string `json:"stp_mode"`
+}
+
+// parseDecimal parses an optional decimal field of a request
+func parseDecimal(field, value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	if !decimalPattern.MatchString(value) {
+		return decimal.Zero, fmt.Errorf("%s must be a decimal string, got %q", field, value)
+	}
+	return decimal.NewFromString(value)
+}
+
+// order builds the engine order of the request
+func (req *createOrderRequest) order(userID string) (*matching.Order, error) {
+	if len(req.ClientOrderID) > 100 {
+		return nil, errors.New("client_order_id must not exceed 100 characters")
+	}
+
+	order := &matching.Order{
+		UserID:          userID,
+		ClientOrderID:   req.ClientOrderID,
+		Symbol:          req.Symbol,
+		Side:            matching.Side(req.Side),
+		OrderType:       matching.OrderType(req.OrderType),
+		TimeInForce:     matching.TimeInForce(req.TimeInForce),
+		STPMode:         matching.STPMode(req.STPMode),
+		TrailingPercent: req.TrailingPercent,
+	}
+
+	switch order.Side {
+	case matching.SideBuy, matching.SideSell:
+	default:
+		return nil, fmt.Errorf("invalid side: %s", req.Side)
+	}
+
+	if !validOrderType(order.OrderType) {
+		return nil, fmt.Errorf("invalid order_type: %s", req.OrderType)
+	}
+
+	switch order.TimeInForce {
+	case "":
+		order.TimeInForce = matching.TimeInForceGTC
+	case matching.TimeInForceGTC, matching.TimeInForceIOC, matching.TimeInForceFOK:
+	default:
+		return nil, fmt.Errorf("invalid time_in_force: %s", req.TimeInForce)
+	}
+
+	switch order.STPMode {
+	case matching.STPModeNone, matching.STPModeCancelNewest, matching.STPModeCancelOldest,
+		matching.STPModeCancelBoth, matching.STPModeDecrementAndCancel:
+	default:
+		return nil, fmt.Errorf("invalid stp_mode: %s", req.STPMode)
+	}
+
+	fields := []struct {
+		name  string
+		value string
+		dst   *decimal.Decimal
+	}{
+		{"quantity", req.Quantity, &order.Quantity},
+		{"price", req.Price, &order.Price},
+		{"stop_price", req.StopPrice, &order.StopPrice},
+		{"trailing_offset", req.TrailingOffset, &order.TrailingOffset},
+	}
+	for _, f := range fields {
+		d, err := parseDecimal(f.name, f.value)
+		if err != nil {
+			return nil, err
+		}
+		*f.dst = d
+	}
+
+	return order, nil
+}
+
+// orderResponse is the OrderResponse schema of the API spec
+type orderResponse struct {
+	OrderID        string                `json:"order_id"`
+	ClientOrderID  string                `json:"client_order_id"`
+	UserID         string                `json:"user_id"`
+	Symbol         string                `json:"symbol"`
+	Side           matching.Side         `json:"side"`
+	OrderType      matching.OrderType    `json:"order_type"`
+	Status         matching.OrderStatus  `json:"status"`
+	StatusReason   matching.StatusReason `json:"status_reason,omitempty"`
+	Quantity       string                `json:"quantity"`
+	FilledQuantity string                `json:"filled_quantity"`
+	Price          *string               `json:"price"`
+	AveragePrice   *string               `json:"average_price"`
+	StopPrice      *string               `json:"stop_price"`
+	TimeInForce    matching.TimeInForce  `json:"time_in_force"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+	Sequence       uint64                `json:"sequence"`
+	GlobalSequence uint64                `json:"global_sequence"`
+}
+
+// optionalDecimal renders a decimal that is unset when zero
+func optionalDecimal(d decimal.Decimal) *string {
+	if d.IsZero() {
+		return nil
+	}
+	s := d.String()
+	return &s
+}
+
+// newOrderResponse renders order with the average price of its fills
+func newOrderResponse(order *matching.Order, fills []*matching.Trade) orderResponse {
+	value, quantity := decimal.Zero, decimal.Zero
+	for _, trade := range fills {
+		value = value.Add(trade.Price.Mul(trade.Quantity))
+		quantity = quantity.Add(trade.Quantity)
+	}
+
+	var average *string
+	if quantity.IsPositive() {
+		average = optionalDecimal(value.Div(quantity))
+	}
+
+	return orderResponse{
+		OrderID:        order.OrderID,
+		ClientOrderID:  order.ClientOrderID,
+		UserID:         order.UserID,
+		Symbol:         order.Symbol,
+		Side:           order.Side,
+		OrderType:      order.OrderType,
+		Status:         order.Status,
+		StatusReason:   order.StatusReason,
+		Quantity:       order.Quantity.String(),
+		FilledQuantity: order.FilledQuantity.String(),
+		Price:          optionalDecimal(order.Price),
+		AveragePrice:   average,
+		StopPrice:      optionalDecimal(order.StopPrice),
+		TimeInForce:    order.TimeInForce,
+		CreatedAt:      order.CreatedAt,
+		UpdatedAt:      order.UpdatedAt,
+		Sequence:       order.Sequence,
+		GlobalSequence: order.GlobalSequence,
+	}
+}
+
+// tradeResponse is the TradeResponse schema: a trade from the point of view
+// of one of its orders
+type tradeResponse struct {
+	TradeID        string        `json:"trade_id"`
+	Symbol         string        `json:"symbol"`
+	Side           matching.Side `json:"side"`
+	OrderID        string        `json:"order_id"`
+	Price          string        `json:"price"`
+	Quantity       string        `json:"quantity"`
+	Fee            string        `json:"fee"`
+	FeeAsset       string        `json:"fee_asset"`
+	IsMaker        bool          `json:"is_maker"`
+	ExecutedAt     time.Time     `json:"executed_at"`
+	Sequence       uint64        `json:"sequence"`
+	GlobalSequence uint64        `json:"global_sequence"`
+}
+
+// newTradeResponse renders trade for its buy or sell order
+func newTradeResponse(trade *matching.Trade, side matching.Side) tradeResponse {
+	resp := tradeResponse{
+		TradeID:        trade.TradeID,
+		Symbol:         trade.Symbol,
+		Side:           side,
+		Price:          trade.Price.String(),
+		Quantity:       trade.Quantity.String(),
+		FeeAsset:       quoteAsset(trade.Symbol), // Fees are charged on the trade value
+		ExecutedAt:     trade.ExecutedAt,
+		Sequence:       trade.Sequence,
+		GlobalSequence: trade.GlobalSequence,
+	}
+
+	if side == matching.SideBuy {
+		resp.OrderID = trade.BuyerOrderID
+		resp.Fee = trade.BuyerFee.String()
+		resp.IsMaker = trade.IsBuyerMaker
+	} else {
+		resp.OrderID = trade.SellerOrderID
+		resp.Fee = trade.SellerFee.String()
+		resp.IsMaker = !trade.IsBuyerMaker
+	}
+	return resp
+}
+
+// quoteAsset returns the quote currency of a "BASE/QUOTE" symbol
+func quoteAsset(symbol string) string {
+	if i := strings.LastIndex(symbol, "/"); i >= 0 {
+		return symbol[i+1:]
+	}
+	return ""
+}
+
+// newFillResponses renders the fills of order
+func newFillResponses(order *matching.Order, fills []*matching.Trade) []tradeResponse {
+	resp := make([]tradeResponse, 0, len(fills))
+	for _, trade := range fills {
+		resp = append(resp, newTradeResponse(trade, order.Side))
+	}
+	return resp
+}
+
+// fillsOf returns the trades order took part in
+func fillsOf(order *matching.Order, trades []*matching.Trade) []*matching.Trade {
+	fills := make([]*matching.Trade, 0, len(trades))
+	for _, trade := range trades {
+		if trade.BuyerOrderID == order.OrderID || trade.SellerOrderID == order.OrderID {
+			fills = append(fills, trade)
+		}
+	}
+	return fills
+}
+
+// ============================================================================
+// HANDLERS
+// ============================================================================
+
+type orderAPI struct {
+	engine *matching.MatchingEngine
+	orders *matching.OrderStore
+}
+
+// registerOrderRoutes adds the order endpoints to the /api/v1 group
+func registerOrderRoutes(v1 *gin.RouterGroup, engine *matching.MatchingEngine, orders *matching.OrderStore) {
+	api := &orderAPI{engine: engine, orders: orders}
+
+	v1.POST("/orders", api.placeOrder)
+	v1.GET("/orders", api.listOrders)
+	v1.GET("/orders/:order_id", api.getOrder)
+	v1.DELETE("/orders", api.cancelAllOrders)
+	v1.DELETE("/orders/:order_id", api.cancelOrder)
+}
+
+func (api *orderAPI) placeOrder(c *gin.Context) {
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req createOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeProblem(c, http.StatusBadRequest, "invalid-request", "Invalid Request", err.Error())
+		return
+	}
+
+	order, err := req.order(userID)
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, "invalid-request", "Invalid Request", err.Error())
+		return
+	}
+
+	// The order as the place command left it: a resting order may already
+	// be trading against newer orders
+	future := api.engine.SubmitOrder(order)
+	trades, err := future.Wait()
+	if err != nil {
+		writeEngineError(c, err)
+		return
+	}
+	placed := future.Order()
+
+	fills := fillsOf(placed, trades)
+	resp := struct {
+		orderResponse
+		Trades []tradeResponse `json:"trades"`
+	}{
+		orderResponse: newOrderResponse(placed, fills),
+		Trades:        newFillResponses(placed, fills),
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// getOrder returns an order of the user with its fills. Other users' orders
+// are reported as not found.
+func (api *orderAPI) getOrder(c *gin.Context) {
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+
+	stored, ok := api.orders.Get(c.Param("order_id"))
+	if !ok || stored.Order.UserID != userID {
+		writeProblem(c, http.StatusNotFound, "not-found", "Not Found", "order not found")
+		return
+	}
+
+	resp := struct {
+		orderResponse
+		Fills []tradeResponse `json:"fills"`
+	}{
+		orderResponse: newOrderResponse(stored.Order, stored.Fills),
+		Fills:         newFillResponses(stored.Order, stored.Fills),
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// cancelOrder cancels an order of the user and returns its final state
+func (api *orderAPI) cancelOrder(c *gin.Context) {
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+
+	stored, ok := api.orders.Get(c.Param("order_id"))
+	if !ok || stored.Order.UserID != userID {
+		writeProblem(c, http.StatusNotFound, "not-found", "Not Found", "order not found")
+		return
+	}
+	order := stored.Order
+
+	if err := api.engine.CancelOrder(order.OrderID, order.Symbol, userID); err != nil {
+		if matching.ErrorCodeOf(err) == matching.ErrCodeOrderNotFound {
+			// The store knows the order, so it has left the book
+			stored, _ = api.orders.Get(order.OrderID)
+			err = &matching.OrderError{
+				Code:    matching.ErrCodeOrderNotActive,
+				Message: fmt.Sprintf("order is %s", stored.Order.Status),
+			}
+		}
+		writeEngineError(c, err)
+		return
+	}
+
+	stored, _ = api.orders.Get(order.OrderID)
+	c.JSON(http.StatusOK, newOrderResponse(stored.Order, stored.Fills))
+}
+
+// cancelAllOrders cancels the user's open orders, optionally only those on
+// one symbol and/or side, and reports them in one response
+func (api *orderAPI) cancelAllOrders(c *gin.Context) {
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+
+	symbol := c.Query("symbol")
+	side := matching.Side(c.Query("side"))
+	if side != "" && side != matching.SideBuy && side != matching.SideSell {
+		writeProblem(c, http.StatusBadRequest, "invalid-request", "Invalid Request", "invalid side: "+string(side))
+		return
+	}
+
+	cancelled, err := api.engine.CancelAllOrders(userID, symbol, side)
+	if err != nil {
+		writeEngineError(c, err)
+		return
+	}
+
+	data := make([]orderResponse, 0, len(cancelled))
+	for _, order := range cancelled {
+		stored, _ := api.orders.Get(order.OrderID)
+		data = append(data, newOrderResponse(order, stored.Fills))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cancelled_count": len(data),
+		"orders":          data,
+	})
+}
+
+// listOrders returns a page of the user's orders, newest first
+func (api *orderAPI) listOrders(c *gin.Context) {
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+
+	q, err := parseOrderQuery(c, userID)
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, "invalid-request", "Invalid Request", err.Error())
+		return
+	}
+
+	page, err := api.orders.Query(q)
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, "invalid-request", "Invalid Request", err.Error())
+		return
+	}
+
+	data := make([]orderResponse, 0, len(page.Orders))
+	for _, stored := range page.Orders {
+		data = append(data, newOrderResponse(stored.Order, stored.Fills))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+		"pagination": gin.H{
+			"limit":       q.Limit,
+			"next_cursor": page.NextCursor,
+			"has_next":    page.NextCursor != "",
+		},
+	})
+}
+
+// parseOrderQuery reads the filters of GET /orders
+func parseOrderQuery(c *gin.Context, userID string) (matching.OrderQuery, error) {
+	q := matching.OrderQuery{
+		UserID:    userID,
+		Symbol:    c.Query("symbol"),
+		Status:    matching.OrderStatus(c.Query("status")),
+		OrderType: matching.OrderType(c.Query("order_type")),
+		Cursor:    c.Query("cursor"),
+	}
+
+	if q.Status != "" && !validOrderStatus(q.Status) {
+		return q, fmt.Errorf("invalid status: %s", q.Status)
+	}
+	if q.OrderType != "" && !validOrderType(q.OrderType) {
+		return q, fmt.Errorf("invalid order_type: %s", q.OrderType)
+	}
+
+	var err error
+	if q.Limit, err = parseLimit(c, matching.DefaultOrderQueryLimit, matching.MaxOrderQueryLimit); err != nil {
+		return q, err
+	}
+	if q.From, q.To, err = parseDateRange(c); err != nil {
+		return q, err
+	}
+
+	return q, nil
+}
+
+// parseLimit reads the limit query parameter, def when absent
+func parseLimit(c *gin.Context, def, max int) (int, error) {
+	s := c.Query("limit")
+	if s == "" {
+		return def, nil
+	}
+
+	limit, err := strconv.Atoi(s)
+	if err != nil || limit < 1 || limit > max {
+		return 0, fmt.Errorf("limit must be between 1 and %d", max)
+	}
+	return limit, nil
+}
+
+// parseDateRange reads the start_date and end_date query parameters
+func parseDateRange(c *gin.Context) (from, to time.Time, err error) {
+	for _, f := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"start_date", &from},
+		{"end_date", &to},
+	} {
+		if s := c.Query(f.name); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return from, to, fmt.Errorf("%s must be an RFC 3339 time, got %q", f.name, s)
+			}
+			*f.dst = t
+		}
+	}
+	return from, to, nil
+}
+
+func validOrderType(t matching.OrderType) bool {
+	switch t {
+	case matching.OrderTypeMarket, matching.OrderTypeLimit, matching.OrderTypeStop,
+		matching.OrderTypeStopLimit, matching.OrderTypeTrailingStop:
+		return true
+	}
+	return false
+}
+
+func validOrderStatus(s matching.OrderStatus) bool {
+	switch s {
+	case matching.OrderStatusPending, matching.OrderStatusOpen, matching.OrderStatusPartiallyFilled,
+		matching.OrderStatusTriggered, matching.OrderStatusFilled, matching.OrderStatusCancelled,
+		matching.OrderStatusRejected:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,293 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - ORDER API TESTS
+// ============================================================================
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mytrader/trade-engine/internal/matching"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAPI returns an API router over an engine trading BTC/USDT, with
+// the routes setupRouter registers for orders and trades
+func newTestAPI(t *testing.T) (*gin.Engine, *matching.MatchingEngine) {
+	gin.SetMode(gin.TestMode)
+
+	engine := matching.NewMatchingEngine()
+	t.Cleanup(engine.Close)
+
+	spec, err := matching.NewSymbolSpec("0.01", "0.0001", "0.0001", "100", "10", "")
+	require.NoError(t, err)
+	engine.GetOrCreateOrderBook("BTC/USDT")
+	require.NoError(t, engine.SetSymbolSpec("BTC/USDT", spec))
+
+	orders, err := matching.NewOrderStore(engine)
+	require.NoError(t, err)
+	trades, err := matching.NewTradeStore(engine, 0)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.UseRawPath = true
+	v1 := router.Group("/api/v1")
+	registerOrderRoutes(v1, engine, orders)
+	registerTradeRoutes(v1, engine, trades)
+	return router, engine
+}
+
+// request sends an API request as user (none when empty) and decodes the
+// JSON response
+func request(t *testing.T, router *gin.Engine, method, path, user, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		req.Header.Set(userIDHeader, user)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return w, resp
+}
+
+func placeOrder(t *testing.T, router *gin.Engine, user, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	return request(t, router, http.MethodPost, "/api/v1/orders", user, body)
+}
+
+func TestOrderAPI_PlaceOrder(t *testing.T) {
+	router, _ := newTestAPI(t)
+
+	w, ask := placeOrder(t, router, "alice", `{"client_order_id":"c1","symbol":"BTC/USDT","side":"SELL",
+		"order_type":"LIMIT","quantity":"1.5","price":"50000.00"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "OPEN", ask["status"])
+	assert.Equal(t, "c1", ask["client_order_id"])
+	assert.Equal(t, "alice", ask["user_id"])
+	assert.Equal(t, "GTC", ask["time_in_force"])
+	assert.Equal(t, "0", ask["filled_quantity"])
+	assert.Nil(t, ask["average_price"])
+	assert.Empty(t, ask["trades"])
+
+	w, bid := placeOrder(t, router, "bob", `{"symbol":"BTC/USDT","side":"BUY","order_type":"MARKET",
+		"quantity":"1","time_in_force":"IOC"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "FILLED", bid["status"])
+	assert.Equal(t, "1", bid["filled_quantity"])
+	assert.Equal(t, "50000", bid["average_price"])
+	assert.Nil(t, bid["price"])
+
+	trades := bid["trades"].([]interface{})
+	require.Equal(t, 1, len(trades))
+	trade := trades[0].(map[string]interface{})
+	assert.Equal(t, "BUY", trade["side"])
+	assert.Equal(t, bid["order_id"], trade["order_id"])
+	assert.Equal(t, false, trade["is_maker"])
+	assert.Equal(t, "50", trade["fee"]) // 0.10% taker fee
+	assert.Equal(t, "USDT", trade["fee_asset"])
+}
+
+func TestOrderAPI_PlaceOrder_InvalidRequest(t *testing.T) {
+	router, _ := newTestAPI(t)
+
+	tests := []struct {
+		name   string
+		user   string
+		body   string
+		status int
+		typ    string
+	}{
+		{"no user", "", `{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT","quantity":"1","price":"50000"}`,
+			http.StatusUnauthorized, "unauthorized"},
+		{"missing field", "alice", `{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT"}`,
+			http.StatusBadRequest, "invalid-request"},
+		{"malformed quantity", "alice", `{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT","quantity":"1,5","price":"50000"}`,
+			http.StatusBadRequest, "invalid-request"},
+		{"negative price", "alice", `{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT","quantity":"1","price":"-50000"}`,
+			http.StatusBadRequest, "invalid-request"},
+		{"invalid time in force", "alice", `{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT","quantity":"1","price":"50000","time_in_force":"DAY"}`,
+			http.StatusBadRequest, "invalid-request"},
+		{"unknown symbol", "alice", `{"symbol":"DOGE/USDT","side":"BUY","order_type":"LIMIT","quantity":"1","price":"1"}`,
+			http.StatusBadRequest, "unknown-symbol"},
+		{"missing limit price", "alice", `{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT","quantity":"1"}`,
+			http.StatusBadRequest, "invalid-order"},
+		{"tick size", "alice", `{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT","quantity":"1","price":"50000.001"}`,
+			http.StatusBadRequest, "invalid-tick-size"},
+		{"fill or kill", "alice", `{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT","quantity":"1","price":"50000","time_in_force":"FOK"}`,
+			http.StatusConflict, "fok-not-filled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, problem := placeOrder(t, router, tt.user, tt.body)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+			assert.Equal(t, problemTypeBase+tt.typ, problem["type"])
+			assert.Equal(t, float64(tt.status), problem["status"])
+			assert.Equal(t, "/api/v1/orders", problem["instance"])
+			assert.NotEmpty(t, problem["title"])
+			assert.NotEmpty(t, problem["detail"])
+		})
+	}
+}
+
+func TestOrderAPI_PlaceOrder_SymbolHalted(t *testing.T) {
+	router, engine := newTestAPI(t)
+	require.NoError(t, engine.SetSymbolStatus("BTC/USDT", matching.SymbolStatusHalted, "incident"))
+
+	w, problem := placeOrder(t, router, "alice", `{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT",
+		"quantity":"1","price":"50000"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, problemTypeBase+"symbol-halted", problem["type"])
+	assert.Equal(t, string(matching.ErrCodeSymbolHalted), problem["code"])
+}
+
+func TestOrderAPI_GetOrder(t *testing.T) {
+	router, engine := newTestAPI(t)
+
+	_, ask := placeOrder(t, router, "alice", `{"symbol":"BTC/USDT","side":"SELL","order_type":"LIMIT",
+		"quantity":"1","price":"50000"}`)
+	_, bid := placeOrder(t, router, "bob", `{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT",
+		"quantity":"0.4","price":"50000"}`)
+	askID := ask["order_id"].(string)
+
+	// The fill happened after the ask was placed
+	w, order := request(t, router, http.MethodGet, "/api/v1/orders/"+askID, "alice", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "PARTIALLY_FILLED", order["status"])
+	assert.Equal(t, "0.4", order["filled_quantity"])
+	assert.Equal(t, "50000", order["average_price"])
+	fills := order["fills"].([]interface{})
+	require.Equal(t, 1, len(fills))
+	fill := fills[0].(map[string]interface{})
+	assert.Equal(t, "SELL", fill["side"])
+	assert.Equal(t, true, fill["is_maker"])
+	assert.Equal(t, bid["trades"].([]interface{})[0].(map[string]interface{})["trade_id"], fill["trade_id"])
+
+	// Terminal orders can still be queried
+	require.NoError(t, engine.CancelOrder(askID, "BTC/USDT", "alice"))
+	_, order = request(t, router, http.MethodGet, "/api/v1/orders/"+askID, "alice", "")
+	assert.Equal(t, "CANCELLED", order["status"])
+
+	// Other users' orders do not exist for them
+	w, problem := request(t, router, http.MethodGet, "/api/v1/orders/"+askID, "bob", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, problemTypeBase+"not-found", problem["type"])
+
+	w, _ = request(t, router, http.MethodGet, "/api/v1/orders/no-such-order", "alice", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestOrderAPI_ListOrders(t *testing.T) {
+	router, _ := newTestAPI(t)
+
+	var ids []string
+	for _, price := range []string{"49000", "49100", "49200"} {
+		_, order := placeOrder(t, router, "alice", `{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT",
+			"quantity":"1","price":"`+price+`"}`)
+		ids = append(ids, order["order_id"].(string))
+	}
+	placeOrder(t, router, "bob", `{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT","quantity":"1","price":"49000"}`)
+
+	list := func(query string) (int, []string, map[string]interface{}) {
+		w, resp := request(t, router, http.MethodGet, "/api/v1/orders"+query, "alice", "")
+		if w.Code != http.StatusOK {
+			return w.Code, nil, resp
+		}
+		var got []string
+		for _, order := range resp["data"].([]interface{}) {
+			got = append(got, order.(map[string]interface{})["order_id"].(string))
+		}
+		return w.Code, got, resp["pagination"].(map[string]interface{})
+	}
+
+	_, got, pagination := list("?limit=2")
+	assert.Equal(t, []string{ids[2], ids[1]}, got)
+	assert.Equal(t, true, pagination["has_next"])
+
+	_, got, pagination = list("?limit=2&cursor=" + pagination["next_cursor"].(string))
+	assert.Equal(t, []string{ids[0]}, got)
+	assert.Equal(t, false, pagination["has_next"])
+
+	_, got, _ = list("?symbol=BTC/USDT&status=OPEN&order_type=LIMIT")
+	assert.Equal(t, 3, len(got))
+	_, got, _ = list("?status=FILLED")
+	assert.Empty(t, got)
+
+	for _, query := range []string{"?limit=0", "?limit=101", "?status=DONE", "?cursor=x", "?start_date=yesterday"} {
+		code, _, problem := list(query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+		assert.Equal(t, problemTypeBase+"invalid-request", problem["type"], query)
+	}
+}
+
+func TestOrderAPI_CancelOrder(t *testing.T) {
+	router, _ := newTestAPI(t)
+
+	_, ask := placeOrder(t, router, "alice", `{"symbol":"BTC/USDT","side":"SELL","order_type":"LIMIT",
+		"quantity":"1","price":"50000"}`)
+	askID := ask["order_id"].(string)
+
+	// Other users' orders do not exist for them
+	w, problem := request(t, router, http.MethodDelete, "/api/v1/orders/"+askID, "bob", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, problemTypeBase+"not-found", problem["type"])
+
+	w, order := request(t, router, http.MethodDelete, "/api/v1/orders/"+askID, "alice", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "CANCELLED", order["status"])
+	assert.Equal(t, askID, order["order_id"])
+
+	// Orders no longer in the book cannot be cancelled
+	w, problem = request(t, router, http.MethodDelete, "/api/v1/orders/"+askID, "alice", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, problemTypeBase+"order-not-active", problem["type"])
+	assert.Equal(t, string(matching.ErrCodeOrderNotActive), problem["code"])
+
+	w, _ = request(t, router, http.MethodDelete, "/api/v1/orders/no-such-order", "alice", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestOrderAPI_CancelAllOrders(t *testing.T) {
+	router, _ := newTestAPI(t)
+
+	for _, body := range []string{
+		`{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT","quantity":"1","price":"49000"}`,
+		`{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT","quantity":"1","price":"49100"}`,
+		`{"symbol":"BTC/USDT","side":"SELL","order_type":"LIMIT","quantity":"1","price":"51000"}`,
+	} {
+		w, _ := placeOrder(t, router, "alice", body)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+	placeOrder(t, router, "bob", `{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT","quantity":"1","price":"49000"}`)
+
+	w, resp := request(t, router, http.MethodDelete, "/api/v1/orders?symbol=BTC/USDT&side=BUY", "alice", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, float64(2), resp["cancelled_count"])
+	for _, order := range resp["orders"].([]interface{}) {
+		order := order.(map[string]interface{})
+		assert.Equal(t, "CANCELLED", order["status"])
+		assert.Equal(t, "BUY", order["side"])
+	}
+
+	_, resp = request(t, router, http.MethodDelete, "/api/v1/orders", "alice", "")
+	assert.Equal(t, float64(1), resp["cancelled_count"])
+
+	_, resp = request(t, router, http.MethodGet, "/api/v1/orders?status=OPEN", "bob", "")
+	assert.Equal(t, 1, len(resp["data"].([]interface{})))
+
+	for _, query := range []string{"?side=BOTH", "?symbol=DOGE/USDT"} {
+		w, _ := request(t, router, http.MethodDelete, "/api/v1/orders"+query, "alice", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
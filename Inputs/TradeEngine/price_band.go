@@ -0,0 +1,89 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - PRICE BAND PROTECTION
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Market Protection)
+// Description: Rejects limit orders priced too far from the reference price
+//              and caps how far market orders may walk the book (RMR-004)
+// ============================================================================
+
+package matching
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// withinLimit reports whether a resting price is acceptable for side given
+// a limit (BUY: price <= limit, SELL: price >= limit)
+func withinLimit(side Side, price, limit decimal.Decimal) bool {
+	if side == SideBuy {
+		return price.LessThanOrEqual(limit)
+	}
+	return price.GreaterThanOrEqual(limit)
+}
+
+// ReferencePrice returns the last trade price, falling back to the mid price
+// when the symbol has not traded yet. Zero means no reference is available.
+func (ob *OrderBook) ReferencePrice() decimal.Decimal {
+	ob.mu.RLock()
+	lastPrice := ob.LastPrice
+	ob.mu.RUnlock()
+
+	if lastPrice.IsPositive() {
+		return lastPrice
+	}
+
+	bestBid := ob.GetBestBid()
+	bestAsk := ob.GetBestAsk()
+	if bestBid.IsZero() || bestAsk.IsZero() {
+		return decimal.Zero
+	}
+	return bestBid.Add(bestAsk).Div(decimal.NewFromInt(2))
+}
+
+// priceBand returns the lower and upper band prices. ok is false when the
+// band is disabled or there is no reference price.
+func (ob *OrderBook) priceBand() (lower, upper decimal.Decimal, ok bool) {
+	percent := ob.GetSpec().PriceBandPercent
+	if !percent.IsPositive() {
+		return decimal.Zero, decimal.Zero, false
+	}
+
+	ref := ob.ReferencePrice()
+	if ref.IsZero() {
+		return decimal.Zero, decimal.Zero, false
+	}
+
+	width := ref.Mul(percent).Div(hundred)
+	return ref.Sub(width), ref.Add(width), true
+}
+
+// checkPriceBand rejects a limit price outside the symbol's price band
+func (ob *OrderBook) checkPriceBand(price decimal.Decimal) error {
+	lower, upper, ok := ob.priceBand()
+	if !ok {
+		return nil
+	}
+
+	if price.LessThan(lower) || price.GreaterThan(upper) {
+		return newOrderError(ErrCodePriceOutOfBand,
+			"price %s is outside the price band [%s, %s]", price, lower, upper)
+	}
+	return nil
+}
+
+// marketProtectionPrice returns the worst price a market order on side may
+// trade at, or zero when no band applies
+func (ob *OrderBook) marketProtectionPrice(side Side) decimal.Decimal {
+	lower, upper, ok := ob.priceBand()
+	if !ok {
+		return decimal.Zero
+	}
+
+	if side == SideBuy {
+		return upper
+	}
+	return lower
+}
@@ -0,0 +1,146 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - PRICE BAND TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBandEngine returns an engine with a ±10% price band on BTC/USDT
+func newTestBandEngine(t *testing.T) *MatchingEngine {
+	spec, err := NewSymbolSpec("", "", "", "", "", "10")
+	require.NoError(t, err)
+
+	me := newTestEngine()
+	me.SetSymbolSpec("BTC/USDT", spec)
+	return me
+}
+
+func TestMatchingEngine_PriceBand_NoReference(t *testing.T) {
+	me := newTestBandEngine(t)
+
+	// Empty book and no trades: nothing to compare against
+	_, err := me.PlaceOrder(newTestOrder(SideSell, OrderTypeLimit, "1.0", "90000"))
+	assert.NoError(t, err)
+}
+
+func TestMatchingEngine_PriceBand_LastPrice(t *testing.T) {
+	me := newTestBandEngine(t)
+	tradeAt(t, me, "50000")
+
+	order := newTestOrder(SideSell, OrderTypeLimit, "1.0", "55001")
+	_, err := me.PlaceOrder(order)
+	require.Error(t, err)
+	assert.Equal(t, ErrCodePriceOutOfBand, ErrorCodeOf(err))
+	assert.Equal(t, OrderStatusRejected, order.Status)
+
+	order = newTestOrder(SideBuy, OrderTypeLimit, "1.0", "44999")
+	_, err = me.PlaceOrder(order)
+	assert.Equal(t, ErrCodePriceOutOfBand, ErrorCodeOf(err))
+
+	// Band edges are inclusive
+	_, err = me.PlaceOrder(newTestOrder(SideSell, OrderTypeLimit, "1.0", "55000"))
+	assert.NoError(t, err)
+	_, err = me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "45000"))
+	assert.NoError(t, err)
+}
+
+func TestMatchingEngine_PriceBand_MidPrice(t *testing.T) {
+	me := newTestBandEngine(t)
+
+	// No trades yet: reference is the mid price (50000)
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49000"))
+	me.PlaceOrder(newTestOrder(SideSell, OrderTypeLimit, "1.0", "51000"))
+
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	assert.Equal(t, "50000", ob.ReferencePrice().String())
+
+	_, err := me.PlaceOrder(newTestOrder(SideSell, OrderTypeLimit, "1.0", "56000"))
+	assert.Equal(t, ErrCodePriceOutOfBand, ErrorCodeOf(err))
+}
+
+func TestMatchingEngine_PriceBand_MarketProtection(t *testing.T) {
+	me := newTestBandEngine(t)
+	tradeAt(t, me, "50000")
+
+	me.PlaceOrder(newTestOrder(SideSell, OrderTypeLimit, "1.0", "51000"))
+	me.PlaceOrder(newTestOrder(SideSell, OrderTypeLimit, "1.0", "55000"))
+
+	// Liquidity beyond the band can only be left over from an earlier
+	// reference price; add it to the book directly
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	far := newTestOrder(SideSell, OrderTypeLimit, "1.0", "60000")
+	far.Status = OrderStatusOpen
+	require.NoError(t, ob.AddOrder(far))
+
+	market := newTestMarketOrder(SideBuy, "3.0")
+	trades, err := me.PlaceOrder(market)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(trades))
+	assert.Equal(t, "2", market.FilledQuantity.String())
+	assert.Equal(t, StatusReasonPriceProtection, market.StatusReason)
+	assert.Equal(t, OrderStatusOpen, far.Status)
+	assert.Equal(t, "60000", ob.GetBestAsk().String())
+}
+
+func TestMatchingEngine_PriceBand_MarketFOK(t *testing.T) {
+	me := newTestBandEngine(t)
+	tradeAt(t, me, "50000")
+
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49000"))
+	far := newTestOrder(SideBuy, OrderTypeLimit, "1.0", "40000")
+	far.Status = OrderStatusOpen
+	require.NoError(t, ob.AddOrder(far))
+
+	// Liquidity beyond the band doesn't count towards a FOK fill
+	market := newTestMarketOrder(SideSell, "2.0")
+	market.TimeInForce = TimeInForceFOK
+	_, err := me.PlaceOrder(market)
+	assert.Error(t, err)
+	assert.Equal(t, OrderStatusRejected, market.Status)
+}
+
+func TestMatchingEngine_PriceBand_Amend(t *testing.T) {
+	me := newTestBandEngine(t)
+	tradeAt(t, me, "50000")
+
+	order := newTestOrder(SideSell, OrderTypeLimit, "1.0", "51000")
+	_, err := me.PlaceOrder(order)
+	require.NoError(t, err)
+
+	_, err = me.AmendOrder(order.OrderID, order.Symbol, order.UserID, decimal.NewFromInt(60000), decimal.Zero)
+	assert.Equal(t, ErrCodePriceOutOfBand, ErrorCodeOf(err))
+	assert.Equal(t, "51000", order.Price.String())
+}
+
+func TestMatchingEngine_PriceBand_StopLimit(t *testing.T) {
+	me := newTestBandEngine(t)
+	tradeAt(t, me, "50000")
+
+	// Checked on acceptance
+	stop := newTestStopOrder(SideSell, "1.0", "48000")
+	stop.OrderType = OrderTypeStopLimit
+	stop.Price = decimal.NewFromInt(44000)
+	_, err := me.PlaceOrder(stop)
+	assert.Equal(t, ErrCodePriceOutOfBand, ErrorCodeOf(err))
+	assert.Equal(t, OrderStatusRejected, stop.Status)
+
+	// And again on trigger, against the band around the trigger price
+	stop = newTestStopOrder(SideBuy, "1.0", "52000")
+	stop.OrderType = OrderTypeStopLimit
+	stop.Price = decimal.NewFromInt(46000)
+	_, err = me.PlaceOrder(stop)
+	require.NoError(t, err)
+
+	tradeAt(t, me, "52000")
+	assert.Equal(t, OrderStatusRejected, stop.Status)
+	assert.True(t, stop.FilledQuantity.IsZero())
+}
@@ -0,0 +1,187 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - PRICE LEVEL INDEX
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Order Book Sides)
+// Description: Skip list of price levels kept in priority order (asks
+//              ascending, bids descending): O(log n) insert/delete/lookup,
+//              O(1) best price and sorted iteration for depth snapshots
+// ============================================================================
+
+package matching
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	maxSkipHeight = 32   // Enough for 2^64 levels at p = 1/4
+	skipP         = 0.25 // Probability of promoting a node one level up
+)
+
+type priceNode struct {
+	level *PriceLevel
+	next  []*priceNode // Forward pointers, one per skip list height
+}
+
+// PriceQueue holds one side of the book, best price first
+type PriceQueue struct {
+	head   *priceNode
+	height int
+	length int
+	isAsk  bool // true for asks (lowest price first), false for bids (highest first)
+	fixed  bool // Levels are ordered by fxPrice (fixed-point book)
+	rnd    *rand.Rand
+}
+
+func NewPriceQueue(isAsk bool) *PriceQueue {
+	return &PriceQueue{
+		head:   &priceNode{next: make([]*priceNode, maxSkipHeight)},
+		height: 1,
+		isAsk:  isAsk,
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Len returns the number of price levels
+func (pq *PriceQueue) Len() int { return pq.length }
+
+// Peek returns the best price level, or nil if the side is empty
+func (pq *PriceQueue) Peek() *PriceLevel {
+	if first := pq.head.next[0]; first != nil {
+		return first.level
+	}
+	return nil
+}
+
+// Get returns the level at price, or nil
+func (pq *PriceQueue) Get(price decimal.Decimal) *PriceLevel {
+	return pq.get(priceKey{price: price})
+}
+
+// Insert adds a level; its price must not already be present
+func (pq *PriceQueue) Insert(level *PriceLevel) {
+	var update [maxSkipHeight]*priceNode
+	pq.search(pq.levelKey(level), &update)
+
+	height := pq.randomHeight()
+	if height > pq.height {
+		for h := pq.height; h < height; h++ {
+			update[h] = pq.head
+		}
+		pq.height = height
+	}
+
+	inserted := &priceNode{level: level, next: make([]*priceNode, height)}
+	for h := 0; h < height; h++ {
+		inserted.next[h] = update[h].next[h]
+		update[h].next[h] = inserted
+	}
+	pq.length++
+}
+
+// Remove deletes and returns the level at price, or nil if absent
+func (pq *PriceQueue) Remove(price decimal.Decimal) *PriceLevel {
+	return pq.remove(priceKey{price: price})
+}
+
+// getTicks and removeTicks are the fixed-point book's integer lookups
+func (pq *PriceQueue) getTicks(ticks int64) *PriceLevel {
+	return pq.get(priceKey{ticks: ticks, fixed: true})
+}
+
+func (pq *PriceQueue) removeTicks(ticks int64) *PriceLevel {
+	return pq.remove(priceKey{ticks: ticks, fixed: true})
+}
+
+func (pq *PriceQueue) get(key priceKey) *PriceLevel {
+	if next := pq.search(key, nil).next[0]; next != nil && key.equal(next.level) {
+		return next.level
+	}
+	return nil
+}
+
+func (pq *PriceQueue) remove(key priceKey) *PriceLevel {
+	var update [maxSkipHeight]*priceNode
+	target := pq.search(key, &update).next[0]
+	if target == nil || !key.equal(target.level) {
+		return nil
+	}
+
+	for h := 0; h < len(target.next); h++ {
+		update[h].next[h] = target.next[h]
+	}
+	for pq.height > 1 && pq.head.next[pq.height-1] == nil {
+		pq.height--
+	}
+	pq.length--
+	return target.level
+}
+
+// search returns the last node ordered before key, recording the
+// predecessor at every height in update when non-nil
+func (pq *PriceQueue) search(key priceKey, update *[maxSkipHeight]*priceNode) *priceNode {
+	node := pq.head
+	for h := pq.height - 1; h >= 0; h-- {
+		for node.next[h] != nil && pq.before(node.next[h].level, key) {
+			node = node.next[h]
+		}
+		if update != nil {
+			update[h] = node
+		}
+	}
+	return node
+}
+
+// Each calls fn for every level, best price first, until fn returns false
+func (pq *PriceQueue) Each(fn func(level *PriceLevel) bool) {
+	for node := pq.head.next[0]; node != nil; node = node.next[0] {
+		if !fn(node.level) {
+			return
+		}
+	}
+}
+
+// priceKey addresses a level by decimal price, or by ticks in a
+// fixed-point book
+type priceKey struct {
+	price decimal.Decimal
+	ticks int64
+	fixed bool
+}
+
+func (k priceKey) equal(level *PriceLevel) bool {
+	if k.fixed {
+		return level.fxPrice == k.ticks
+	}
+	return level.Price.Equal(k.price)
+}
+
+func (pq *PriceQueue) levelKey(level *PriceLevel) priceKey {
+	return priceKey{price: level.Price, ticks: level.fxPrice, fixed: pq.fixed}
+}
+
+// before reports whether level has priority over key on this side
+func (pq *PriceQueue) before(level *PriceLevel, key priceKey) bool {
+	if key.fixed {
+		if pq.isAsk {
+			return level.fxPrice < key.ticks
+		}
+		return level.fxPrice > key.ticks
+	}
+	if pq.isAsk {
+		return level.Price.LessThan(key.price)
+	}
+	return level.Price.GreaterThan(key.price)
+}
+
+func (pq *PriceQueue) randomHeight() int {
+	height := 1
+	for height < maxSkipHeight && pq.rnd.Float64() < skipP {
+		height++
+	}
+	return height
+}
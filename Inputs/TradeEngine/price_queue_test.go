@@ -0,0 +1,85 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - PRICE LEVEL INDEX TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queuePrices returns the queue's prices in iteration order
+func queuePrices(pq *PriceQueue) []int64 {
+	prices := make([]int64, 0, pq.Len())
+	pq.Each(func(level *PriceLevel) bool {
+		prices = append(prices, level.Price.IntPart())
+		return true
+	})
+	return prices
+}
+
+func TestPriceQueue_Order(t *testing.T) {
+	asks := NewPriceQueue(true)
+	bids := NewPriceQueue(false)
+
+	for _, p := range []int64{105, 101, 110, 103} {
+		asks.Insert(NewPriceLevel(decimal.NewFromInt(p)))
+		bids.Insert(NewPriceLevel(decimal.NewFromInt(p)))
+	}
+
+	assert.Equal(t, []int64{101, 103, 105, 110}, queuePrices(asks))
+	assert.Equal(t, []int64{110, 105, 103, 101}, queuePrices(bids))
+	assert.Equal(t, "101", asks.Peek().Price.String())
+	assert.Equal(t, "110", bids.Peek().Price.String())
+}
+
+func TestPriceQueue_GetAndRemove(t *testing.T) {
+	pq := NewPriceQueue(true)
+	assert.Nil(t, pq.Peek())
+	assert.Nil(t, pq.Remove(decimal.NewFromInt(100)))
+
+	level := NewPriceLevel(decimal.NewFromInt(100))
+	pq.Insert(level)
+	pq.Insert(NewPriceLevel(decimal.NewFromInt(200)))
+
+	// Lookup is by value, not by representation
+	assert.Same(t, level, pq.Get(decimal.RequireFromString("100.00")))
+	assert.Nil(t, pq.Get(decimal.NewFromInt(150)))
+
+	assert.Same(t, level, pq.Remove(decimal.NewFromInt(100)))
+	assert.Nil(t, pq.Get(decimal.NewFromInt(100)))
+	assert.Equal(t, 1, pq.Len())
+	assert.Equal(t, "200", pq.Peek().Price.String())
+}
+
+func TestPriceQueue_RandomOperations(t *testing.T) {
+	pq := NewPriceQueue(false)
+	rnd := rand.New(rand.NewSource(42))
+	present := make(map[int64]bool)
+
+	for i := 0; i < 5000; i++ {
+		p := rnd.Int63n(500)
+		if present[p] {
+			require.NotNil(t, pq.Remove(decimal.NewFromInt(p)))
+			delete(present, p)
+		} else {
+			pq.Insert(NewPriceLevel(decimal.NewFromInt(p)))
+			present[p] = true
+		}
+	}
+
+	expected := make([]int64, 0, len(present))
+	for p := range present {
+		expected = append(expected, p)
+	}
+	sort.Slice(expected, func(i, j int) bool { return expected[i] > expected[j] })
+
+	assert.Equal(t, len(expected), pq.Len())
+	assert.Equal(t, expected, queuePrices(pq))
+}
@@ -0,0 +1,114 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - REPLAY SUBCOMMAND TESTS
+// ============================================================================
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/mytrader/trade-engine/internal/config"
+	"github.com/mytrader/trade-engine/internal/matching"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replayTrades runs the replay subcommand and returns the trades it prints
+func replayTrades(t *testing.T, cfg *config.Config, args ...string) []*matching.Trade {
+	var out bytes.Buffer
+	require.NoError(t, runReplay(cfg, args, &out))
+
+	trades := make([]*matching.Trade, 0)
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var line replayTrade
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line), scanner.Text())
+		if line.Trade != nil {
+			assert.NotZero(t, line.Entry)
+			trades = append(trades, line.Trade)
+		}
+	}
+	return trades
+}
+
+func assertSameTrades(t *testing.T, expected, actual []*matching.Trade) {
+	require.Equal(t, len(expected), len(actual))
+	for i, want := range expected {
+		got := actual[i]
+		assert.Equal(t, want.TradeID, got.TradeID, "trade %d", i)
+		assert.Equal(t, want.TakerOrderID, got.TakerOrderID, "trade %d", i)
+		assert.Equal(t, want.MakerOrderID, got.MakerOrderID, "trade %d", i)
+		assert.True(t, want.Price.Equal(got.Price), "trade %d price", i)
+		assert.True(t, want.Quantity.Equal(got.Quantity), "trade %d quantity", i)
+		assert.True(t, want.ExecutedAt.Equal(got.ExecutedAt), "trade %d time", i)
+	}
+}
+
+func TestRunReplay_ReproducesTrades(t *testing.T) {
+	for _, fixed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("fixed=%v", fixed), func(t *testing.T) {
+			dir := t.TempDir()
+			journalDir, snapshotDir := filepath.Join(dir, "journal"), filepath.Join(dir, "snapshots")
+
+			cfg := &config.Config{}
+			cfg.Trading.Matching = config.MatchingConfig{TickSize: "0.01", StepSize: "0.0001", FixedPoint: fixed}
+
+			// Original run, journaled, with a snapshot in the middle
+			engine := matching.NewMatchingEngine()
+			require.NoError(t, setupSymbols(engine, cfg))
+			journal, err := matching.OpenJournal(matching.JournalConfig{Dir: journalDir})
+			require.NoError(t, err)
+			_, err = engine.RecoverFromJournal(journal)
+			require.NoError(t, err)
+
+			var mu sync.Mutex
+			var trades []*matching.Trade
+			sub, err := engine.Subscribe(matching.EventSinkFunc(func(event matching.Event) {
+				if e, ok := event.(*matching.TradeEvent); ok {
+					mu.Lock()
+					trades = append(trades, e.Trade)
+					mu.Unlock()
+				}
+			}), matching.SubscriptionConfig{})
+			require.NoError(t, err)
+
+			limitOrder(t, engine, "alice", matching.SideSell, "0.5", "50000")
+			limitOrder(t, engine, "alice", matching.SideSell, "1.0", "50000.5")
+			limitOrder(t, engine, "bob", matching.SideBuy, "0.75", "50000.5") // Two fills, one taker
+
+			// Written without compacting, so the full journal stays replayable
+			snapshot, err := engine.TakeSnapshot()
+			require.NoError(t, err)
+			snapshotPath, err := matching.WriteSnapshot(snapshotDir, snapshot)
+			require.NoError(t, err)
+
+			// A bid rests after taking the last ask and is filled twice
+			limitOrder(t, engine, "bob", matching.SideBuy, "2.0", "50001")
+			limitOrder(t, engine, "carol", matching.SideSell, "0.5", "50000")
+			limitOrder(t, engine, "carol", matching.SideSell, "0.5", "50000")
+
+			sub.Flush()
+			mu.Lock()
+			original := append([]*matching.Trade(nil), trades...)
+			mu.Unlock()
+			require.Equal(t, 5, len(original))
+
+			sub.Close()
+			engine.Close()
+			require.NoError(t, journal.Close())
+
+			// Full replay prints the original trades, IDs included
+			assertSameTrades(t, original, replayTrades(t, cfg, "-journal", journalDir))
+
+			// Starting from the snapshot prints the trades after it, with the
+			// IDs they had in the original run
+			assertSameTrades(t, original[2:], replayTrades(t, cfg, "-snapshot", snapshotPath, "-journal", journalDir))
+		})
+	}
+}
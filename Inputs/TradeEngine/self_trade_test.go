@@ -0,0 +1,172 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - SELF-TRADE PREVENTION TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupSelfTrade places a resting sell for user "alice" followed by a
+// resting sell from another user at the same price
+func setupSelfTrade(t *testing.T, me *MatchingEngine) (own, other *Order) {
+	own = newTestOrder(SideSell, OrderTypeLimit, "1.0", "50000")
+	own.UserID = "alice"
+	other = newTestOrder(SideSell, OrderTypeLimit, "1.0", "50000")
+
+	_, err := me.PlaceOrder(own)
+	require.NoError(t, err)
+	_, err = me.PlaceOrder(other)
+	require.NoError(t, err)
+	return own, other
+}
+
+func newSelfTradeBuy(quantity string, mode STPMode) *Order {
+	buy := newTestOrder(SideBuy, OrderTypeLimit, quantity, "50000")
+	buy.UserID = "alice"
+	buy.STPMode = mode
+	return buy
+}
+
+func TestMatchingEngine_SelfTrade_NoneAllowsSelfTrade(t *testing.T) {
+	me := newTestEngine()
+	own, _ := setupSelfTrade(t, me)
+
+	trades, err := me.PlaceOrder(newSelfTradeBuy("1.0", STPModeNone))
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(trades))
+	assert.Equal(t, own.OrderID, trades[0].SellerOrderID)
+}
+
+func TestMatchingEngine_SelfTrade_CancelNewest(t *testing.T) {
+	me := newTestEngine()
+	own, _ := setupSelfTrade(t, me)
+
+	buy := newSelfTradeBuy("1.0", STPModeCancelNewest)
+	trades, err := me.PlaceOrder(buy)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(trades))
+	assert.Equal(t, OrderStatusCancelled, buy.Status)
+	assert.Equal(t, StatusReasonSelfTrade, buy.StatusReason)
+	assert.Equal(t, OrderStatusOpen, own.Status)
+
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	assert.Equal(t, 2, len(ob.Orders))
+}
+
+func TestMatchingEngine_SelfTrade_CancelOldest(t *testing.T) {
+	me := newTestEngine()
+	own, other := setupSelfTrade(t, me)
+
+	events := recordEvents(t, me)
+
+	buy := newSelfTradeBuy("1.0", STPModeCancelOldest)
+	trades, err := me.PlaceOrder(buy)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(trades))
+	assert.Equal(t, other.OrderID, trades[0].SellerOrderID)
+	assert.Equal(t, OrderStatusFilled, buy.Status)
+
+	// Resting own order is cancelled with a reason code
+	assert.Equal(t, OrderStatusCancelled, own.Status)
+	assert.Equal(t, StatusReasonSelfTrade, own.StatusReason)
+	var cancelled []string
+	for _, event := range events.all() {
+		if e, ok := event.(*OrderCancelledEvent); ok {
+			cancelled = append(cancelled, e.Order.OrderID)
+		}
+	}
+	assert.Equal(t, []string{own.OrderID}, cancelled)
+
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	assert.Equal(t, 0, len(ob.Orders))
+}
+
+func TestMatchingEngine_SelfTrade_CancelBoth(t *testing.T) {
+	me := newTestEngine()
+	own, other := setupSelfTrade(t, me)
+
+	buy := newSelfTradeBuy("1.0", STPModeCancelBoth)
+	trades, err := me.PlaceOrder(buy)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(trades))
+	assert.Equal(t, OrderStatusCancelled, buy.Status)
+	assert.Equal(t, OrderStatusCancelled, own.Status)
+	assert.Equal(t, OrderStatusOpen, other.Status)
+
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	assert.Equal(t, 1, len(ob.Orders))
+	_, asks := ob.GetDepth(10)
+	assert.Equal(t, "1", asks[0][1])
+}
+
+func TestMatchingEngine_SelfTrade_DecrementAndCancel(t *testing.T) {
+	me := newTestEngine()
+	own, other := setupSelfTrade(t, me)
+
+	// Overlap of 1.0 cancels the resting own order; the remaining 0.5 trades
+	buy := newSelfTradeBuy("1.5", STPModeDecrementAndCancel)
+	trades, err := me.PlaceOrder(buy)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(trades))
+	assert.Equal(t, other.OrderID, trades[0].SellerOrderID)
+	assert.Equal(t, "0.5", trades[0].Quantity.String())
+
+	assert.Equal(t, OrderStatusCancelled, own.Status)
+	assert.Equal(t, "0", own.Quantity.String())
+	assert.Equal(t, OrderStatusFilled, buy.Status)
+	assert.Equal(t, "0.5", buy.Quantity.String())
+	assert.Equal(t, StatusReasonSelfTrade, buy.StatusReason)
+
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	_, asks := ob.GetDepth(10)
+	assert.Equal(t, "0.5", asks[0][1])
+}
+
+func TestMatchingEngine_SelfTrade_DecrementRestingOrder(t *testing.T) {
+	me := newTestEngine()
+	own, other := setupSelfTrade(t, me)
+
+	// Incoming is smaller than the overlap: it is cancelled, resting shrinks
+	buy := newSelfTradeBuy("0.4", STPModeDecrementAndCancel)
+	trades, err := me.PlaceOrder(buy)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(trades))
+	assert.Equal(t, OrderStatusCancelled, buy.Status)
+	assert.Equal(t, OrderStatusOpen, own.Status)
+	assert.Equal(t, "0.6", own.Quantity.String())
+	assert.Equal(t, OrderStatusOpen, other.Status)
+
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	_, asks := ob.GetDepth(10)
+	assert.Equal(t, "1.6", asks[0][1])
+}
+
+func TestMatchingEngine_SelfTrade_FOK(t *testing.T) {
+	me := newTestEngine()
+	own, other := setupSelfTrade(t, me)
+
+	// Own liquidity doesn't count towards a FOK fill
+	buy := newSelfTradeBuy("1.5", STPModeCancelOldest)
+	buy.TimeInForce = TimeInForceFOK
+	_, err := me.PlaceOrder(buy)
+	assert.Error(t, err)
+	assert.Equal(t, OrderStatusOpen, own.Status)
+	assert.Equal(t, OrderStatusOpen, other.Status)
+
+	buy = newSelfTradeBuy("1.0", STPModeCancelOldest)
+	buy.TimeInForce = TimeInForceFOK
+	trades, err := me.PlaceOrder(buy)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(trades))
+	assert.Equal(t, OrderStatusFilled, buy.Status)
+}
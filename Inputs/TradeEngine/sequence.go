@@ -0,0 +1,39 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - EVENT SEQUENCING
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Event Ordering)
+// Description: Every engine event (trade, order update, book status change)
+//              is stamped with a per-symbol sequence number and an
+//              engine-wide one, so Kafka / WebSocket consumers and replays
+//              can detect missed or reordered messages.
+// ============================================================================
+
+package matching
+
+// EventSequence orders an event among all events of the engine.
+//
+//   - Sequence is per symbol: strictly monotonic and gap-free, so a consumer
+//     that sees N+2 after N has missed an event of that symbol
+//   - GlobalSequence is engine-wide: strictly monotonic in emission order.
+//     It is allocated under the event bus lock, so every subscriber receives
+//     events in GlobalSequence order (see events.go)
+type EventSequence struct {
+	Sequence       uint64 `json:"sequence"`
+	GlobalSequence uint64 `json:"global_sequence"`
+}
+
+// nextSequence allocates the numbers of the book's next event. Runs on the
+// book's goroutine.
+func (me *MatchingEngine) nextSequence(ob *OrderBook) EventSequence {
+	ob.seq++
+	return EventSequence{
+		Sequence:       ob.seq,
+		GlobalSequence: me.globalSeq.Add(1),
+	}
+}
+
+// GlobalSequence returns the number of the latest event of the engine
+func (me *MatchingEngine) GlobalSequence() uint64 {
+	return me.globalSeq.Load()
+}
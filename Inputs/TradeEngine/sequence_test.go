@@ -0,0 +1,80 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - EVENT SEQUENCING TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequencesBySymbol groups the sequence of every event per symbol, in
+// emission order
+func sequencesBySymbol(events []Event) map[string][]EventSequence {
+	sequences := make(map[string][]EventSequence)
+	for _, event := range events {
+		h := event.Header()
+		sequences[h.Symbol] = append(sequences[h.Symbol], h.EventSequence)
+	}
+	return sequences
+}
+
+func TestMatchingEngine_Sequence_PerSymbolGapFree(t *testing.T) {
+	me := newTestEngine()
+	recorder := recordEvents(t, me)
+
+	for _, symbol := range []string{"BTC/USDT", "ETH/USDT"} {
+		ask := newTestOrder(SideSell, OrderTypeLimit, "1.0", "50000")
+		ask.Symbol = symbol
+		_, err := me.PlaceOrder(ask)
+		require.NoError(t, err)
+
+		bid := newTestOrder(SideBuy, OrderTypeLimit, "0.4", "50000")
+		bid.Symbol = symbol
+		_, err = me.PlaceOrder(bid)
+		require.NoError(t, err)
+
+		require.NoError(t, me.CancelOrder(ask.OrderID, symbol, ask.UserID))
+	}
+	require.NoError(t, me.SetSymbolStatus("ETH/USDT", SymbolStatusMaintenance, "upgrade"))
+
+	var globals []uint64
+	for symbol, sequences := range sequencesBySymbol(recorder.all()) {
+		for i, seq := range sequences {
+			assert.Equal(t, uint64(i+1), seq.Sequence, "%s event %d", symbol, i)
+			if i > 0 {
+				assert.Greater(t, seq.GlobalSequence, sequences[i-1].GlobalSequence)
+			}
+			globals = append(globals, seq.GlobalSequence)
+		}
+	}
+
+	// Engine-wide numbers are unique and gap-free across symbols
+	assert.Equal(t, 19, len(globals))
+	for i := uint64(1); i <= 19; i++ {
+		assert.Contains(t, globals, i)
+	}
+	assert.Equal(t, uint64(19), me.GlobalSequence())
+
+	snapshot := me.GetOrderBookSnapshot("ETH/USDT", 5)
+	assert.Equal(t, uint64(10), snapshot["last_update_id"])
+}
+
+func TestMatchingEngine_Sequence_EmissionOrder(t *testing.T) {
+	me := newTestEngine()
+
+	ask := newTestOrder(SideSell, OrderTypeLimit, "1.0", "50000")
+	me.PlaceOrder(ask)
+
+	trades, err := me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000"))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trades))
+
+	// Ask accepted and its book delta, then the taker accepted; the
+	// maker's fill is reported before the trade
+	assert.Equal(t, uint64(4), ask.Sequence)
+	assert.Equal(t, uint64(5), trades[0].Sequence)
+}
@@ -0,0 +1,440 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - ORDER BOOK SNAPSHOTS
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Durability, NFR-005)
+// Description: Consistent snapshots of every order book (resting orders in
+//              FIFO position, stop watchlist with trigger state, last price,
+//              breaker state, sequence numbers), written on a schedule and at
+//              shutdown. Recovery restores the latest snapshot and replays
+//              only the journal tail; journal segments older than the oldest
+//              retained snapshot are compacted away.
+// ============================================================================
+
+package matching
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// EngineSnapshot is the state of every order book at one point of the
+// journal
+type EngineSnapshot struct {
+	JournalSeq     uint64          `json:"journal_seq"` // Last journal entry applied
+	GlobalSequence uint64          `json:"global_sequence"`
+	TakenAt        time.Time       `json:"taken_at"`
+	Books          []*BookSnapshot `json:"books"`
+}
+
+// BookSnapshot is the state of one order book
+type BookSnapshot struct {
+	Symbol         string               `json:"symbol"`
+	Status         SymbolStatus         `json:"status"`
+	Sequence       uint64               `json:"sequence"` // Per-symbol sequence of the latest event
+	LastPrice      decimal.Decimal      `json:"last_price"`
+	LastUpdateTime time.Time            `json:"last_update_time"`
+	Bids           []*Order             `json:"bids"` // Best price first, FIFO within a price
+	Asks           []*Order             `json:"asks"`
+	StopOrders     []*StopOrderSnapshot `json:"stop_orders"` // Watchlist (arrival) order
+	Breaker        BreakerSnapshot      `json:"breaker"`
+}
+
+// StopOrderSnapshot is an untriggered stop order with its trigger state
+type StopOrderSnapshot struct {
+	*Order
+	TrailReference decimal.Decimal `json:"trail_reference"` // Best price seen by a trailing stop
+}
+
+// BreakerSnapshot is the circuit breaker state of a book (its config is not
+// part of the snapshot)
+type BreakerSnapshot struct {
+	Halted      bool           `json:"halted"`
+	HaltedUntil time.Time      `json:"halted_until"`
+	Window      []BreakerPrice `json:"window"`
+}
+
+// BreakerPrice is a trade price in the breaker's rolling window
+type BreakerPrice struct {
+	Price decimal.Decimal `json:"price"`
+	At    time.Time       `json:"at"`
+}
+
+// ============================================================================
+// CAPTURE / RESTORE
+// ============================================================================
+
+// TakeSnapshot captures every order book at the same point of the journal.
+// Each book's goroutine is parked between commands until all are, so no
+// command runs while the state is copied; the journal is then rotated so
+// the next segment starts right after the snapshot.
+func (me *MatchingEngine) TakeSnapshot() (*EngineSnapshot, error) {
+	release := make(chan struct{})
+	defer close(release)
+
+	parked := make(map[*OrderBook]bool)
+	for {
+		for _, ob := range me.books() {
+			if parked[ob] {
+				continue
+			}
+			if err := ob.park(release); err != nil {
+				return nil, err
+			}
+			parked[ob] = true
+		}
+
+		// Books are only created under me.mu: holding it, a book created
+		// since the last pass shows up here, and no new one can
+		me.mu.RLock()
+		if len(me.OrderBooks) == len(parked) {
+			snapshot, err := me.capture()
+			me.mu.RUnlock()
+			return snapshot, err
+		}
+		me.mu.RUnlock()
+	}
+}
+
+// capture copies the parked books. Caller holds me.mu.
+func (me *MatchingEngine) capture() (*EngineSnapshot, error) {
+	snapshot := &EngineSnapshot{
+		JournalSeq:     me.restoredSeq,
+		GlobalSequence: me.globalSeq.Load(),
+		TakenAt:        me.clock.Now(),
+		Books:          make([]*BookSnapshot, 0, len(me.OrderBooks)),
+	}
+	for _, ob := range me.OrderBooks {
+		snapshot.Books = append(snapshot.Books, ob.snapshot())
+	}
+
+	if me.journal != nil {
+		snapshot.JournalSeq = me.journal.Seq()
+		if err := me.journal.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	return snapshot, nil
+}
+
+// park queues a command that holds the book's goroutine until release is
+// closed, and returns once it does
+func (ob *OrderBook) park(release <-chan struct{}) error {
+	parked := make(chan struct{})
+	future := ob.submit(func() ([]*Trade, error) {
+		close(parked)
+		<-release
+		return nil, nil
+	})
+
+	select {
+	case <-parked:
+		return nil
+	case <-future.Done():
+		_, err := future.Wait()
+		return err
+	}
+}
+
+// snapshot copies the book's state. Runs while the book is parked.
+func (ob *OrderBook) snapshot() *BookSnapshot {
+	bs := &BookSnapshot{
+		Symbol:         ob.Symbol,
+		Status:         ob.Status,
+		Sequence:       ob.seq,
+		LastPrice:      ob.LastPrice,
+		LastUpdateTime: ob.LastUpdateTime,
+		Bids:           snapshotSide(ob.Bids),
+		Asks:           snapshotSide(ob.Asks),
+		StopOrders:     make([]*StopOrderSnapshot, 0, len(ob.StopOrders)),
+		Breaker: BreakerSnapshot{
+			Halted:      ob.Breaker.halted,
+			HaltedUntil: ob.Breaker.haltedUntil,
+			Window:      make([]BreakerPrice, 0, len(ob.Breaker.window)),
+		},
+	}
+
+	for _, order := range ob.StopOrders {
+		bs.StopOrders = append(bs.StopOrders, &StopOrderSnapshot{
+			Order:          copyOrder(order),
+			TrailReference: order.trailRef,
+		})
+	}
+	for _, p := range ob.Breaker.window {
+		bs.Breaker.Window = append(bs.Breaker.Window, BreakerPrice{Price: p.price, At: p.at})
+	}
+	return bs
+}
+
+func snapshotSide(queue *PriceQueue) []*Order {
+	orders := make([]*Order, 0)
+	queue.Each(func(level *PriceLevel) bool {
+		for _, order := range level.Orders() {
+			orders = append(orders, copyOrder(order))
+		}
+		return true
+	})
+	return orders
+}
+
+// copyOrder copies an order without its book links
+func copyOrder(order *Order) *Order {
+	c := *order
+	c.level, c.prev, c.next = nil, nil, nil
+	return &c
+}
+
+// RestoreSnapshot loads a snapshot into an engine whose books are still
+// empty, before RecoverFromJournal replays the journal tail. Symbol specs,
+// fixed-point mode and breaker settings must be configured first.
+func (me *MatchingEngine) RestoreSnapshot(snapshot *EngineSnapshot) error {
+	if me.journal != nil {
+		return errors.New("cannot restore a snapshot with a journal attached")
+	}
+
+	for _, bs := range snapshot.Books {
+		ob := me.GetOrCreateOrderBook(bs.Symbol)
+		if err := ob.do(func() error { return ob.restore(bs) }); err != nil {
+			return fmt.Errorf("restore %s: %w", bs.Symbol, err)
+		}
+	}
+
+	me.globalSeq.Store(snapshot.GlobalSequence)
+	me.restoredSeq = snapshot.JournalSeq
+	return nil
+}
+
+// restore loads a book snapshot. Runs on the book's goroutine.
+func (ob *OrderBook) restore(bs *BookSnapshot) error {
+	if len(ob.Orders) > 0 || len(ob.StopOrders) > 0 || ob.seq > 0 {
+		return errors.New("order book is not empty")
+	}
+
+	// Re-adding in snapshot order rebuilds each level's FIFO queue
+	for _, side := range [][]*Order{bs.Bids, bs.Asks} {
+		for _, snapshotted := range side {
+			order := copyOrder(snapshotted)
+			if err := ob.loadFixed(order); err != nil {
+				return err
+			}
+			if err := ob.AddOrder(order); err != nil {
+				return err
+			}
+		}
+	}
+
+	ob.mu.Lock()
+	for _, stop := range bs.StopOrders {
+		order := copyOrder(stop.Order)
+		order.trailRef = stop.TrailReference
+		ob.StopOrders = append(ob.StopOrders, order)
+		ob.stops.add(order)
+	}
+	ob.mu.Unlock()
+
+	ob.Status = bs.Status
+	ob.seq = bs.Sequence
+	ob.changed = nil // Subscribers start from the snapshot, not a delta
+	ob.LastPrice = bs.LastPrice
+	ob.LastUpdateTime = bs.LastUpdateTime
+
+	ob.Breaker.halted = bs.Breaker.Halted
+	ob.Breaker.haltedUntil = bs.Breaker.HaltedUntil
+	ob.Breaker.window = make([]pricePoint, 0, len(bs.Breaker.Window))
+	for _, p := range bs.Breaker.Window {
+		ob.Breaker.window = append(ob.Breaker.window, pricePoint{price: p.Price, at: p.At})
+	}
+	return nil
+}
+
+// ============================================================================
+// SNAPSHOT FILES
+// ============================================================================
+
+// WriteSnapshot writes a snapshot to dir as snapshot-<journal seq>.json and
+// returns its path. The file appears atomically.
+func WriteSnapshot(dir string, snapshot *EngineSnapshot) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create snapshot directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "snapshot-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("create snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if err := json.NewEncoder(tmp).Encode(snapshot); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("encode snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("sync snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("close snapshot: %w", err)
+	}
+
+	path := filepath.Join(dir, sequencedName("snapshot-", snapshot.JournalSeq, ".json"))
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("rename snapshot: %w", err)
+	}
+	return path, syncDir(dir)
+}
+
+// ReadSnapshot reads a snapshot file
+func ReadSnapshot(path string) (*EngineSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot EngineSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("decode snapshot %s: %w", path, err)
+	}
+	return &snapshot, nil
+}
+
+// LatestSnapshot reads the newest snapshot in dir; nil if there is none
+func LatestSnapshot(dir string) (*EngineSnapshot, error) {
+	seqs, err := listSequenced(dir, "snapshot-", ".json")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list snapshots: %w", err)
+	}
+	if len(seqs) == 0 {
+		return nil, nil
+	}
+
+	return ReadSnapshot(filepath.Join(dir, sequencedName("snapshot-", seqs[len(seqs)-1], ".json")))
+}
+
+// ============================================================================
+// SNAPSHOTTER
+// ============================================================================
+
+// SnapshotConfig configures periodic snapshots
+type SnapshotConfig struct {
+	Dir      string
+	Interval time.Duration // 0 disables the schedule (Save is still available)
+	Retain   int           // Snapshots kept on disk, at least 1
+}
+
+// Snapshotter writes engine snapshots on a schedule, keeps the newest Retain
+// of them and compacts the engine's journal up to the oldest one kept
+type Snapshotter struct {
+	engine *MatchingEngine
+	cfg    SnapshotConfig
+
+	// OnError reports failed scheduled snapshots
+	OnError func(err error)
+
+	mu   sync.Mutex // Serializes Save
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewSnapshotter validates cfg and returns a stopped Snapshotter
+func NewSnapshotter(engine *MatchingEngine, cfg SnapshotConfig) (*Snapshotter, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("snapshot directory is required")
+	}
+	if cfg.Interval < 0 {
+		return nil, errors.New("snapshot interval must not be negative")
+	}
+	if cfg.Retain < 1 {
+		cfg.Retain = 1
+	}
+
+	return &Snapshotter{engine: engine, cfg: cfg}, nil
+}
+
+// Start begins writing snapshots every Interval
+func (s *Snapshotter) Start() {
+	if s.cfg.Interval == 0 || s.done != nil {
+		return
+	}
+
+	s.done = make(chan struct{})
+	s.wg.Add(1)
+	go s.loop()
+}
+
+func (s *Snapshotter) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.Save(); err != nil && s.OnError != nil {
+				s.OnError(err)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Stop ends the schedule; a snapshot in progress completes first
+func (s *Snapshotter) Stop() {
+	if s.done == nil {
+		return
+	}
+
+	close(s.done)
+	s.wg.Wait()
+	s.done = nil
+}
+
+// Save takes and writes a snapshot now, then prunes old snapshots and
+// journal segments. Returns the journal entry the snapshot covers.
+func (s *Snapshotter) Save() (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.engine.TakeSnapshot()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := WriteSnapshot(s.cfg.Dir, snapshot); err != nil {
+		return 0, err
+	}
+	return snapshot.JournalSeq, s.prune()
+}
+
+// prune deletes snapshots beyond Retain and the journal segments no
+// retained snapshot needs
+func (s *Snapshotter) prune() error {
+	seqs, err := listSequenced(s.cfg.Dir, "snapshot-", ".json")
+	if err != nil {
+		return fmt.Errorf("list snapshots: %w", err)
+	}
+	if len(seqs) > s.cfg.Retain {
+		for _, seq := range seqs[:len(seqs)-s.cfg.Retain] {
+			if err := os.Remove(filepath.Join(s.cfg.Dir, sequencedName("snapshot-", seq, ".json"))); err != nil {
+				return fmt.Errorf("delete snapshot: %w", err)
+			}
+		}
+		seqs = seqs[len(seqs)-s.cfg.Retain:]
+	}
+
+	if s.engine.journal == nil || len(seqs) == 0 {
+		return nil
+	}
+	_, err = s.engine.journal.Compact(seqs[0])
+	return err
+}
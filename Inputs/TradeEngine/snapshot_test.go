@@ -0,0 +1,254 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - ORDER BOOK SNAPSHOT TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stopState is the trigger state of a watched stop order
+type stopState struct {
+	OrderID   string
+	StopPrice string
+	TrailRef  string
+}
+
+func stopStates(t *testing.T, me *MatchingEngine, symbol string) []stopState {
+	stops := make([]stopState, 0)
+	require.True(t, me.ReadOrderBook(symbol, func(ob *OrderBook) {
+		for _, order := range ob.StopOrders {
+			stops = append(stops, stopState{order.OrderID, order.StopPrice.String(), order.trailRef.String()})
+		}
+	}))
+	return stops
+}
+
+// snapshotBook returns the snapshot of symbol's book
+func snapshotBook(t *testing.T, snapshot *EngineSnapshot, symbol string) *BookSnapshot {
+	for _, bs := range snapshot.Books {
+		if bs.Symbol == symbol {
+			return bs
+		}
+	}
+	require.FailNow(t, "no book snapshot", symbol)
+	return nil
+}
+
+func TestMatchingEngine_SnapshotRecovery(t *testing.T) {
+	for _, fixed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("fixed=%v", fixed), func(t *testing.T) {
+			dir := t.TempDir()
+			journalDir, snapshotDir := filepath.Join(dir, "journal"), filepath.Join(dir, "snapshots")
+			breaker, err := NewCircuitBreakerConfig("10", time.Minute, 5*time.Minute)
+			require.NoError(t, err)
+
+			newEngine := func() *MatchingEngine {
+				me := newTestFixedEngine(t, fixed)
+				me.SetCircuitBreaker("BTC/USDT", breaker)
+				return me
+			}
+
+			// Original run: state before the snapshot
+			me := newEngine()
+			j := openTestJournal(t, journalDir)
+			_, err = me.RecoverFromJournal(j)
+			require.NoError(t, err)
+
+			tradeAt(t, me, "50000")
+			tradeAt(t, me, "50800")
+
+			stop := newTestStopOrder(SideSell, "1.0", "0")
+			stop.OrderType = OrderTypeTrailingStop
+			stop.TrailingOffset = decimal.NewFromInt(500)
+			_, err = me.PlaceOrder(stop)
+			require.NoError(t, err)
+
+			bid := newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49000")
+			for _, order := range []*Order{
+				newTestOrder(SideSell, OrderTypeLimit, "1.0", "51000"),
+				newTestOrder(SideSell, OrderTypeLimit, "1.0", "51000"),
+				bid,
+			} {
+				_, err := me.PlaceOrder(order)
+				require.NoError(t, err)
+			}
+
+			snapshotter, err := NewSnapshotter(me, SnapshotConfig{Dir: snapshotDir, Retain: 2})
+			require.NoError(t, err)
+			seq, err := snapshotter.Save()
+			require.NoError(t, err)
+			assert.Equal(t, uint64(8), seq)
+
+			// Journal tail: a fill across the FIFO queue at 51000, a pull-back
+			// the trailing stop must ignore and a cancel
+			_, err = me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.5", "51000"))
+			require.NoError(t, err)
+			tradeAt(t, me, "50600")
+			require.NoError(t, me.CancelOrder(bid.OrderID, "BTC/USDT", bid.UserID))
+			assert.Equal(t, "50500", stop.StopPrice.String())
+
+			wantSnapshot, wantOrders := bookState(t, me, "BTC/USDT")
+			wantStops := stopStates(t, me, "BTC/USDT")
+			wantGlobal := me.GlobalSequence()
+			me.Close()
+			require.NoError(t, j.Close())
+
+			// Restart from the snapshot plus the tail
+			recovered := newEngine()
+			snapshot, err := LatestSnapshot(snapshotDir)
+			require.NoError(t, err)
+			require.NotNil(t, snapshot)
+			stops := snapshotBook(t, snapshot, "BTC/USDT").StopOrders
+			require.Equal(t, 1, len(stops))
+			assert.Equal(t, "50800", stops[0].TrailReference.String())
+			require.NoError(t, recovered.RestoreSnapshot(snapshot))
+
+			j = openTestJournal(t, journalDir)
+			defer j.Close()
+			replayed, err := recovered.RecoverFromJournal(j)
+			require.NoError(t, err)
+			assert.Equal(t, 4, replayed)
+
+			gotSnapshot, gotOrders := bookState(t, recovered, "BTC/USDT")
+			assert.Equal(t, wantSnapshot, gotSnapshot)
+			assert.Equal(t, wantOrders, gotOrders)
+			assert.Equal(t, wantStops, stopStates(t, recovered, "BTC/USDT"))
+			assert.Equal(t, wantGlobal, recovered.GlobalSequence())
+		})
+	}
+}
+
+func TestSnapshotter_RetainAndCompact(t *testing.T) {
+	dir := t.TempDir()
+	journalDir, snapshotDir := filepath.Join(dir, "journal"), filepath.Join(dir, "snapshots")
+
+	me := newTestEngine()
+	j := openTestJournal(t, journalDir)
+	defer j.Close()
+	_, err := me.RecoverFromJournal(j)
+	require.NoError(t, err)
+
+	snapshotter, err := NewSnapshotter(me, SnapshotConfig{Dir: snapshotDir, Retain: 2})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000"))
+		require.NoError(t, err)
+		_, err = snapshotter.Save()
+		require.NoError(t, err)
+	}
+
+	// Two newest snapshots kept; segments before the older one deleted
+	snapshots, err := listSequenced(snapshotDir, "snapshot-", ".json")
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{2, 3}, snapshots)
+
+	segments, err := listSequenced(journalDir, "journal-", ".log")
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{3, 4}, segments)
+	assert.Equal(t, uint64(2), j.Start())
+
+	entries := journalEntries(t, j)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, uint64(3), entries[0].Seq)
+
+	// Without a snapshot, the compacted journal cannot rebuild the books
+	_, err = newTestEngine().RecoverFromJournal(j)
+	assert.Error(t, err)
+}
+
+func TestMatchingEngine_TakeSnapshot_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	journalDir, snapshotDir := filepath.Join(dir, "journal"), filepath.Join(dir, "snapshots")
+	symbols := []string{"BTC/USDT", "ETH/USDT"}
+
+	me := newTestEngine()
+	j := openTestJournal(t, journalDir)
+	_, err := me.RecoverFromJournal(j)
+	require.NoError(t, err)
+
+	snapshotter, err := NewSnapshotter(me, SnapshotConfig{Dir: snapshotDir, Retain: 1})
+	require.NoError(t, err)
+
+	// Snapshots taken while both books trade must each be a consistent cut
+	var wg sync.WaitGroup
+	for _, symbol := range symbols {
+		for _, side := range []Side{SideBuy, SideSell} {
+			wg.Add(1)
+			go func(symbol string, side Side) {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					order := newTestOrder(side, OrderTypeLimit, "0.5", fmt.Sprintf("%d", 49990+i%20))
+					order.Symbol = symbol
+					me.PlaceOrder(order)
+				}
+			}(symbol, side)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for saving := true; saving; {
+		select {
+		case <-done:
+			saving = false
+		default:
+			_, err := snapshotter.Save()
+			require.NoError(t, err)
+		}
+	}
+
+	want := make(map[string]map[string]orderState)
+	for _, symbol := range symbols {
+		_, want[symbol] = bookState(t, me, symbol)
+	}
+	wantGlobal := me.GlobalSequence()
+	me.Close()
+	require.NoError(t, j.Close())
+
+	recovered := newTestEngine()
+	snapshot, err := LatestSnapshot(snapshotDir)
+	require.NoError(t, err)
+	require.NoError(t, recovered.RestoreSnapshot(snapshot))
+
+	j = openTestJournal(t, journalDir)
+	defer j.Close()
+	_, err = recovered.RecoverFromJournal(j)
+	require.NoError(t, err)
+
+	for _, symbol := range symbols {
+		_, got := bookState(t, recovered, symbol)
+		assert.Equal(t, want[symbol], got, symbol)
+	}
+	assert.Equal(t, wantGlobal, recovered.GlobalSequence())
+}
+
+func TestMatchingEngine_RestoreSnapshot_NonEmpty(t *testing.T) {
+	me := newTestEngine()
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000"))
+
+	snapshot, err := me.TakeSnapshot()
+	require.NoError(t, err)
+	require.Equal(t, len(testSymbols), len(snapshot.Books))
+	assert.Equal(t, 1, len(snapshotBook(t, snapshot, "BTC/USDT").Bids))
+
+	// Restoring only fills books that are still empty
+	assert.Error(t, me.RestoreSnapshot(snapshot))
+
+	other := newTestEngine()
+	require.NoError(t, other.RestoreSnapshot(snapshot))
+	assert.Equal(t, "50000", other.GetOrderBookSnapshot("BTC/USDT", 5)["best_bid"])
+}
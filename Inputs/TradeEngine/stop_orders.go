@@ -0,0 +1,332 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - STOP ORDERS
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Stop Order Watchlist)
+// Description: Stop orders wait outside the book and are converted to
+//              market orders when the last trade price crosses StopPrice
+//              (FR-010, Requirements 4.3). STOP_LIMIT and TRAILING_STOP
+//              are the Phase 2 variants (Requirements 4.4).
+// ============================================================================
+
+package matching
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// ============================================================================
+// STOP ORDER WATCHLIST (per OrderBook)
+// ============================================================================
+
+// stopWatchlist indexes the untriggered stop orders of a book. STOP and
+// STOP_LIMIT orders are kept ordered by trigger price, so a trade only checks
+// the front of each side. Trailing stops move with every trade price and are
+// checked in full.
+type stopWatchlist struct {
+	buys     []*Order // Lowest StopPrice first: crossed first by a rising price
+	sells    []*Order // Highest StopPrice first: crossed first by a falling price
+	trailing []*Order // Arrival order
+	arrivals uint64   // Arrival number of the latest stop (Order.stopSeq)
+}
+
+func stopBuyBefore(a, b decimal.Decimal) bool  { return a.LessThan(b) }
+func stopSellBefore(a, b decimal.Decimal) bool { return a.GreaterThan(b) }
+
+func (w *stopWatchlist) add(order *Order) {
+	w.arrivals++
+	order.stopSeq = w.arrivals
+
+	switch {
+	case order.OrderType == OrderTypeTrailingStop:
+		w.trailing = append(w.trailing, order)
+	case order.Side == SideBuy:
+		w.buys = insertStop(w.buys, order, stopBuyBefore)
+	default:
+		w.sells = insertStop(w.sells, order, stopSellBefore)
+	}
+}
+
+func (w *stopWatchlist) remove(order *Order) {
+	switch {
+	case order.OrderType == OrderTypeTrailingStop:
+		w.trailing = removeStop(w.trailing, order, 0)
+	case order.Side == SideBuy:
+		w.buys = removeStop(w.buys, order, sort.Search(len(w.buys), func(i int) bool {
+			return !stopBuyBefore(w.buys[i].StopPrice, order.StopPrice)
+		}))
+	default:
+		w.sells = removeStop(w.sells, order, sort.Search(len(w.sells), func(i int) bool {
+			return !stopSellBefore(w.sells[i].StopPrice, order.StopPrice)
+		}))
+	}
+}
+
+// insertStop inserts order after the stops triggering before or with it
+func insertStop(stops []*Order, order *Order, before func(a, b decimal.Decimal) bool) []*Order {
+	i := sort.Search(len(stops), func(i int) bool { return before(order.StopPrice, stops[i].StopPrice) })
+	stops = append(stops, nil)
+	copy(stops[i+1:], stops[i:])
+	stops[i] = order
+	return stops
+}
+
+// removeStop removes order, searching from index from
+func removeStop(stops []*Order, order *Order, from int) []*Order {
+	for i := from; i < len(stops); i++ {
+		if stops[i] == order {
+			return append(stops[:i], stops[i+1:]...)
+		}
+	}
+	return stops
+}
+
+// pop removes and returns the next stop triggered at price, or nil. Each
+// side fires in trigger price order; between the sides and the trailing
+// stops, the earlier arrival goes first.
+func (w *stopWatchlist) pop(price decimal.Decimal) *Order {
+	var next *Order
+	consider := func(order *Order) {
+		if isStopTriggered(order, price) && (next == nil || order.stopSeq < next.stopSeq) {
+			next = order
+		}
+	}
+
+	if len(w.buys) > 0 {
+		consider(w.buys[0])
+	}
+	if len(w.sells) > 0 {
+		consider(w.sells[0])
+	}
+	for _, order := range w.trailing {
+		trailStopPrice(order, price)
+		consider(order)
+	}
+
+	if next != nil {
+		w.remove(next)
+	}
+	return next
+}
+
+// AddStopOrder adds an untriggered stop order to the watchlist
+func (ob *OrderBook) AddStopOrder(order *Order) error {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	for _, existing := range ob.StopOrders {
+		if existing.OrderID == order.OrderID {
+			return errors.New("order already exists in stop watchlist")
+		}
+	}
+
+	ob.StopOrders = append(ob.StopOrders, order)
+	ob.stops.add(order)
+	ob.LastUpdateTime = ob.commandTime()
+
+	return nil
+}
+
+// RemoveStopOrder removes an untriggered stop order from the watchlist
+func (ob *OrderBook) RemoveStopOrder(orderID string) (*Order, error) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	for _, order := range ob.StopOrders {
+		if order.OrderID == orderID {
+			ob.removeStop(order)
+			ob.LastUpdateTime = ob.commandTime()
+			return order, nil
+		}
+	}
+
+	return nil, errors.New("stop order not found")
+}
+
+// removeStop drops a stop from the watchlist. Called under mu.
+func (ob *OrderBook) removeStop(order *Order) {
+	ob.StopOrders = removeStop(ob.StopOrders, order, 0)
+	ob.stops.remove(order)
+}
+
+// trailStops moves the trailing stops to follow a trade price. Called for
+// every trade, so a stop follows the prices a command passes through and
+// not only the last one.
+func (ob *OrderBook) trailStops(price decimal.Decimal) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	for _, order := range ob.stops.trailing {
+		trailStopPrice(order, price)
+	}
+}
+
+// popTriggeredStop removes and returns the next stop order triggered at
+// price, or nil if none is. Trailing stops are moved to follow price before
+// their trigger is checked.
+func (ob *OrderBook) popTriggeredStop(price decimal.Decimal) *Order {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	order := ob.stops.pop(price)
+	if order != nil {
+		ob.StopOrders = removeStop(ob.StopOrders, order, 0)
+	}
+	return order
+}
+
+// isStopTriggered checks the trigger condition for a stop order:
+//   - Stop Loss (SELL): price <= StopPrice
+//   - Stop Buy (BUY):   price >= StopPrice
+func isStopTriggered(order *Order, price decimal.Decimal) bool {
+	if price.IsZero() {
+		return false // No trade yet, nothing to compare against
+	}
+
+	if order.Side == SideBuy {
+		return price.GreaterThanOrEqual(order.StopPrice)
+	}
+	return price.LessThanOrEqual(order.StopPrice)
+}
+
+// ============================================================================
+// TRAILING STOP
+// ============================================================================
+
+// validateTrailingOffset checks the offset of a TRAILING_STOP order
+func validateTrailingOffset(order *Order) error {
+	if order.TrailingOffset.LessThanOrEqual(decimal.Zero) {
+		return errors.New("trailing stop must have positive trailing offset")
+	}
+
+	if order.TrailingPercent && order.TrailingOffset.GreaterThanOrEqual(decimal.NewFromInt(100)) {
+		return errors.New("trailing stop percentage must be below 100")
+	}
+
+	return nil
+}
+
+// trailStopPrice moves a trailing stop's StopPrice when price improves on the
+// best price seen so far (highest for SELL, lowest for BUY). StopPrice never
+// moves against the order.
+func trailStopPrice(order *Order, price decimal.Decimal) {
+	if price.IsZero() {
+		return
+	}
+
+	improved := order.trailRef.IsZero() ||
+		(order.Side == SideSell && price.GreaterThan(order.trailRef)) ||
+		(order.Side == SideBuy && price.LessThan(order.trailRef))
+	if !improved {
+		return
+	}
+
+	order.trailRef = price
+
+	offset := order.TrailingOffset
+	if order.TrailingPercent {
+		offset = price.Mul(order.TrailingOffset).Div(decimal.NewFromInt(100))
+	}
+
+	if order.Side == SideSell {
+		order.StopPrice = price.Sub(offset)
+	} else {
+		order.StopPrice = price.Add(offset)
+	}
+}
+
+// ============================================================================
+// STOP ORDER EXECUTION
+// ============================================================================
+
+// placeStopOrder validates a new stop order and adds it to the watchlist
+func (me *MatchingEngine) placeStopOrder(order *Order, ob *OrderBook) error {
+	if order.OrderType == OrderTypeTrailingStop {
+		// Trailing stops are anchored to the last trade price
+		if ob.LastPrice.IsZero() {
+			return fmt.Errorf("trailing stop requires a last trade price for %s", ob.Symbol)
+		}
+		// A sell trigger an absolute offset of the price or more below it
+		// would be zero or negative
+		if order.Side == SideSell && !order.TrailingPercent && order.TrailingOffset.GreaterThanOrEqual(ob.LastPrice) {
+			return fmt.Errorf("trailing stop offset must be below the last trade price %s", ob.LastPrice)
+		}
+		order.trailRef = decimal.Zero
+		trailStopPrice(order, ob.LastPrice)
+	}
+
+	// Stop price validation: a stop that is already crossed would fire at once
+	if isStopTriggered(order, ob.LastPrice) {
+		return errors.New("stop price would trigger immediately")
+	}
+
+	return ob.AddStopOrder(order)
+}
+
+// processStopTriggers fires every stop order crossed by LastPrice.
+//
+// Triggered stops execute one at a time, in trigger price order per side
+// (see stopWatchlist.pop). Each execution can move LastPrice again, so the watchlist is re-checked
+// after every trigger until no further stop is crossed (cascade).
+func (me *MatchingEngine) processStopTriggers(ob *OrderBook) {
+	for {
+		// Stops only fire during normal trading; they wait for the resume
+		if ob.Status != SymbolStatusActive {
+			return
+		}
+
+		order := ob.popTriggeredStop(ob.LastPrice)
+		if order == nil {
+			return
+		}
+
+		me.executeStopOrder(order, ob)
+	}
+}
+
+// executeStopOrder converts a triggered stop order into a market order, or
+// into a limit order at Price for STOP_LIMIT. The price band has moved
+// since a STOP_LIMIT was accepted, so its price is checked again.
+func (me *MatchingEngine) executeStopOrder(order *Order, ob *OrderBook) {
+	order.Status = OrderStatusTriggered
+	order.UpdatedAt = ob.now
+
+	err := ob.checkFillOrKill(order)
+	if err == nil && order.OrderType == OrderTypeStopLimit {
+		err = ob.checkPriceBand(order.Price)
+	}
+	if err != nil {
+		order.Status = OrderStatusRejected
+		me.emitOrderRejected(ob, order, err)
+		return
+	}
+	me.emitOrderAccepted(ob, order, false)
+
+	// Trades are reported as trade events; the order keeps its stop OrderType
+	if order.OrderType == OrderTypeStopLimit {
+		order.Status = OrderStatusOpen // Any remainder rests in the book
+		_, err = me.matchLimitOrder(order, ob)
+	} else {
+		_, err = me.matchMarketOrder(order, ob)
+	}
+
+	if err == nil {
+		updateFillStatus(order)
+	}
+	order.UpdatedAt = ob.now
+
+	switch {
+	case err != nil:
+		order.Status = OrderStatusRejected
+		me.emitOrderRejected(ob, order, err)
+	case order.Status == OrderStatusOpen:
+		// A stop-limit order resting without fills
+		me.emitOrderAccepted(ob, order, false)
+	default:
+		me.emitOrderResult(ob, order)
+	}
+}
@@ -0,0 +1,358 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - STOP ORDER TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// TEST HELPERS
+// ============================================================================
+
+func newTestStopOrder(side Side, quantity, stopPrice string) *Order {
+	order := newTestMarketOrder(side, quantity)
+	order.OrderType = OrderTypeStop
+	order.StopPrice, _ = decimal.NewFromString(stopPrice)
+	return order
+}
+
+// tradeAt sets LastPrice by crossing a resting limit order at price
+func tradeAt(t *testing.T, me *MatchingEngine, price string) {
+	sell := newTestOrder(SideSell, OrderTypeLimit, "0.1", price)
+	_, err := me.PlaceOrder(sell)
+	require.NoError(t, err)
+
+	buy := newTestOrder(SideBuy, OrderTypeLimit, "0.1", price)
+	trades, err := me.PlaceOrder(buy)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trades))
+}
+
+// ============================================================================
+// STOP ORDER TESTS
+// ============================================================================
+
+func TestMatchingEngine_StopOrder_RestsInWatchlist(t *testing.T) {
+	me := newTestEngine()
+	tradeAt(t, me, "50000")
+
+	stop := newTestStopOrder(SideSell, "1.0", "49000")
+	trades, err := me.PlaceOrder(stop)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(trades))
+	assert.Equal(t, OrderStatusOpen, stop.Status)
+
+	// Stop orders are not part of the visible book
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	assert.Equal(t, 1, len(ob.StopOrders))
+	assert.Equal(t, 0, len(ob.Orders))
+}
+
+func TestMatchingEngine_StopOrder_Validation(t *testing.T) {
+	me := newTestEngine()
+	tradeAt(t, me, "50000")
+
+	// Missing stop price
+	stop := newTestStopOrder(SideSell, "1.0", "0")
+	_, err := me.PlaceOrder(stop)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "positive stop price")
+
+	// Sell stop above the last price would fire immediately
+	stop = newTestStopOrder(SideSell, "1.0", "51000")
+	_, err = me.PlaceOrder(stop)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "trigger immediately")
+	assert.Equal(t, OrderStatusRejected, stop.Status)
+}
+
+func TestMatchingEngine_StopOrder_TriggersAsMarket(t *testing.T) {
+	me := newTestEngine()
+	tradeAt(t, me, "50000")
+
+	stop := newTestStopOrder(SideSell, "1.0", "49500")
+	events := recordEvents(t, me)
+
+	_, err := me.PlaceOrder(stop)
+	require.NoError(t, err)
+
+	// Liquidity for the stop to sell into once triggered
+	bid := newTestOrder(SideBuy, OrderTypeLimit, "2.0", "49000")
+	_, err = me.PlaceOrder(bid)
+	require.NoError(t, err)
+
+	// Trade at 49500 crosses the stop price
+	tradeAt(t, me, "49500")
+
+	assert.Equal(t, OrderStatusFilled, stop.Status)
+	assert.Equal(t, "1", stop.FilledQuantity.String())
+	var statuses []OrderStatus
+	for _, order := range events.orders() {
+		if order.OrderID == stop.OrderID {
+			statuses = append(statuses, order.Status)
+		}
+	}
+	assert.Equal(t, []OrderStatus{OrderStatusOpen, OrderStatusTriggered, OrderStatusFilled}, statuses)
+
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	assert.Equal(t, 0, len(ob.StopOrders))
+	assert.Equal(t, "49000", ob.LastPrice.String())
+	assert.Equal(t, "1", bid.RemainingQuantity().String())
+}
+
+func TestMatchingEngine_StopOrder_Cascade(t *testing.T) {
+	me := newTestEngine()
+	tradeAt(t, me, "50000")
+
+	// Stop 1 fires at 49500 and sells into the 49000 bid,
+	// which in turn crosses stop 2 at 49200
+	stop1 := newTestStopOrder(SideSell, "1.0", "49500")
+	stop2 := newTestStopOrder(SideSell, "1.0", "49200")
+	_, err := me.PlaceOrder(stop1)
+	require.NoError(t, err)
+	_, err = me.PlaceOrder(stop2)
+	require.NoError(t, err)
+
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49000"))
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "48000"))
+
+	events := recordEvents(t, me)
+	tradeAt(t, me, "49500")
+
+	var triggered []string
+	for _, order := range events.orders() {
+		if order.Status == OrderStatusTriggered {
+			triggered = append(triggered, order.OrderID)
+		}
+	}
+
+	assert.Equal(t, []string{stop1.OrderID, stop2.OrderID}, triggered)
+	assert.Equal(t, OrderStatusFilled, stop1.Status)
+	assert.Equal(t, OrderStatusFilled, stop2.Status)
+
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	assert.Equal(t, "48000", ob.LastPrice.String())
+}
+
+func TestMatchingEngine_StopOrder_TriggerPriceOrder(t *testing.T) {
+	me := newTestEngine()
+	tradeAt(t, me, "50000")
+
+	// Both stops are crossed by one trade: the one crossed first fires first
+	low := newTestStopOrder(SideSell, "1.0", "49200")
+	high := newTestStopOrder(SideSell, "1.0", "49500")
+	for _, stop := range []*Order{low, high} {
+		_, err := me.PlaceOrder(stop)
+		require.NoError(t, err)
+	}
+
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "3.0", "49000"))
+
+	events := recordEvents(t, me)
+	_, err := me.PlaceOrder(newTestMarketOrder(SideSell, "0.1"))
+	require.NoError(t, err)
+
+	var triggered []string
+	for _, order := range events.orders() {
+		if order.Status == OrderStatusTriggered {
+			triggered = append(triggered, order.OrderID)
+		}
+	}
+	assert.Equal(t, []string{high.OrderID, low.OrderID}, triggered)
+
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	assert.Empty(t, ob.StopOrders)
+	assert.Empty(t, ob.stops.sells)
+}
+
+func TestMatchingEngine_StopOrder_BuyStop(t *testing.T) {
+	me := newTestEngine()
+	tradeAt(t, me, "50000")
+
+	stop := newTestStopOrder(SideBuy, "0.5", "50500")
+	_, err := me.PlaceOrder(stop)
+	require.NoError(t, err)
+
+	me.PlaceOrder(newTestOrder(SideSell, OrderTypeLimit, "1.0", "51000"))
+
+	// Below the stop price: nothing happens
+	tradeAt(t, me, "50400")
+	assert.Equal(t, OrderStatusOpen, stop.Status)
+
+	tradeAt(t, me, "50500")
+	assert.Equal(t, OrderStatusFilled, stop.Status)
+}
+
+func TestMatchingEngine_StopOrder_Cancel(t *testing.T) {
+	me := newTestEngine()
+	tradeAt(t, me, "50000")
+
+	stop := newTestStopOrder(SideSell, "1.0", "49000")
+	_, err := me.PlaceOrder(stop)
+	require.NoError(t, err)
+
+	err = me.CancelOrder(stop.OrderID, stop.Symbol, stop.UserID)
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStatusCancelled, stop.Status)
+
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	assert.Equal(t, 0, len(ob.StopOrders))
+
+	// A cancelled stop never fires
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "48000"))
+	tradeAt(t, me, "48500")
+	assert.Equal(t, OrderStatusCancelled, stop.Status)
+}
+
+// ============================================================================
+// STOP-LIMIT & TRAILING STOP TESTS
+// ============================================================================
+
+func TestMatchingEngine_StopLimitOrder_RestsAtLimitPrice(t *testing.T) {
+	me := newTestEngine()
+	tradeAt(t, me, "50000")
+
+	// Sell stop-limit: trigger at 49500, sell no lower than 49400
+	stop := newTestStopOrder(SideSell, "1.0", "49500")
+	stop.OrderType = OrderTypeStopLimit
+	stop.Price = decimal.NewFromInt(49400)
+	_, err := me.PlaceOrder(stop)
+	require.NoError(t, err)
+
+	// Only 0.4 of bids at or above the limit price
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "0.4", "49450"))
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49000"))
+
+	tradeAt(t, me, "49500")
+
+	assert.Equal(t, OrderStatusPartiallyFilled, stop.Status)
+	assert.Equal(t, "0.4", stop.FilledQuantity.String())
+
+	// Remainder rests as a limit order at Price
+	ob := me.GetOrCreateOrderBook("BTC/USDT")
+	assert.Equal(t, "49400", ob.GetBestAsk().String())
+	assert.Contains(t, ob.Orders, stop.OrderID)
+
+	err = me.CancelOrder(stop.OrderID, stop.Symbol, stop.UserID)
+	assert.NoError(t, err)
+}
+
+func TestMatchingEngine_StopLimitOrder_Validation(t *testing.T) {
+	me := newTestEngine()
+
+	stop := newTestStopOrder(SideSell, "1.0", "49500")
+	stop.OrderType = OrderTypeStopLimit
+	_, err := me.PlaceOrder(stop)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "stop-limit order must have positive price")
+}
+
+func TestMatchingEngine_TrailingStop_Absolute(t *testing.T) {
+	me := newTestEngine()
+	tradeAt(t, me, "50000")
+
+	stop := newTestStopOrder(SideSell, "1.0", "0")
+	stop.OrderType = OrderTypeTrailingStop
+	stop.TrailingOffset = decimal.NewFromInt(1000)
+	_, err := me.PlaceOrder(stop)
+	require.NoError(t, err)
+	assert.Equal(t, "49000", stop.StopPrice.String())
+
+	// Market rallies: trigger follows
+	tradeAt(t, me, "52000")
+	assert.Equal(t, "51000", stop.StopPrice.String())
+
+	// Pull-back above the trigger: trigger stays put
+	tradeAt(t, me, "51500")
+	assert.Equal(t, "51000", stop.StopPrice.String())
+	assert.Equal(t, OrderStatusOpen, stop.Status)
+
+	// Drop through the trailed trigger
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50500"))
+	tradeAt(t, me, "51000")
+	assert.Equal(t, OrderStatusFilled, stop.Status)
+}
+
+func TestMatchingEngine_TrailingStop_FollowsEveryTrade(t *testing.T) {
+	me := newTestEngine()
+	tradeAt(t, me, "50000")
+
+	stop := newTestStopOrder(SideBuy, "1.0", "0")
+	stop.OrderType = OrderTypeTrailingStop
+	stop.TrailingOffset = decimal.NewFromInt(1000)
+	_, err := me.PlaceOrder(stop)
+	require.NoError(t, err)
+
+	// One sell sweeps three bids; the trigger follows the trades down
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "0.5", "49000"))
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "0.5", "48000"))
+	me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "0.5", "47000"))
+	trades, err := me.PlaceOrder(newTestMarketOrder(SideSell, "1.5"))
+	require.NoError(t, err)
+	require.Equal(t, 3, len(trades))
+
+	assert.Equal(t, "48000", stop.StopPrice.String())
+	assert.Equal(t, "47000", stop.trailRef.String())
+	assert.Equal(t, OrderStatusOpen, stop.Status)
+}
+
+func TestMatchingEngine_TrailingStop_Percent(t *testing.T) {
+	me := newTestEngine()
+	tradeAt(t, me, "50000")
+
+	stop := newTestStopOrder(SideBuy, "1.0", "0")
+	stop.OrderType = OrderTypeTrailingStop
+	stop.TrailingOffset = decimal.NewFromInt(2)
+	stop.TrailingPercent = true
+	_, err := me.PlaceOrder(stop)
+	require.NoError(t, err)
+	assert.Equal(t, "51000", stop.StopPrice.String())
+
+	// Market falls: buy trigger follows it down (45000 + 2%)
+	tradeAt(t, me, "45000")
+	assert.Equal(t, "45900", stop.StopPrice.String())
+	assert.Equal(t, OrderStatusOpen, stop.Status)
+}
+
+func TestMatchingEngine_TrailingStop_Validation(t *testing.T) {
+	me := newTestEngine()
+
+	// No reference price yet
+	stop := newTestStopOrder(SideSell, "1.0", "0")
+	stop.OrderType = OrderTypeTrailingStop
+	stop.TrailingOffset = decimal.NewFromInt(100)
+	_, err := me.PlaceOrder(stop)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "requires a last trade price")
+
+	// Missing offset
+	stop = newTestStopOrder(SideSell, "1.0", "0")
+	stop.OrderType = OrderTypeTrailingStop
+	_, err = me.PlaceOrder(stop)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "positive trailing offset")
+
+	// Percentage out of range
+	stop.TrailingOffset = decimal.NewFromInt(100)
+	stop.TrailingPercent = true
+	_, err = me.PlaceOrder(stop)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "below 100")
+
+	// A sell offset of the price or more would put the trigger at or below zero
+	tradeAt(t, me, "50000")
+	stop = newTestStopOrder(SideSell, "1.0", "0")
+	stop.OrderType = OrderTypeTrailingStop
+	stop.TrailingOffset = decimal.NewFromInt(50000)
+	_, err = me.PlaceOrder(stop)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "below the last trade price")
+}
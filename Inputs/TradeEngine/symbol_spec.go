@@ -0,0 +1,155 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - SYMBOL TRADING RULES
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Per-Symbol Specification)
+// Description: Tick size, lot size, min/max order size and min notional
+//              enforced per OrderBook (FR-001, RMR-002), plus the price
+//              band width used by price protection (RMR-004)
+// ============================================================================
+
+package matching
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// SymbolSpec holds the trading rules of a symbol. A zero field disables
+// the corresponding rule.
+type SymbolSpec struct {
+	TickSize    decimal.Decimal `json:"tick_size"`       // Price increment
+	StepSize    decimal.Decimal `json:"step_size"`       // Quantity increment (lot size)
+	MinQuantity decimal.Decimal `json:"min_order_size"`  // Minimum order quantity
+	MaxQuantity decimal.Decimal `json:"max_order_size"`  // Maximum order quantity
+	MinNotional decimal.Decimal `json:"min_order_value"` // Minimum price * quantity (quote currency)
+
+	PriceBandPercent decimal.Decimal `json:"price_band_percentage"` // Max deviation from reference price (±%)
+}
+
+// NewSymbolSpec parses a SymbolSpec from decimal strings (as found in
+// config.yaml). Empty strings leave the rule disabled.
+func NewSymbolSpec(tickSize, stepSize, minQuantity, maxQuantity, minNotional, priceBandPercent string) (SymbolSpec, error) {
+	var spec SymbolSpec
+
+	fields := []struct {
+		name  string
+		value string
+		dest  *decimal.Decimal
+	}{
+		{"tick_size", tickSize, &spec.TickSize},
+		{"step_size", stepSize, &spec.StepSize},
+		{"min_order_size", minQuantity, &spec.MinQuantity},
+		{"max_order_size", maxQuantity, &spec.MaxQuantity},
+		{"min_order_value", minNotional, &spec.MinNotional},
+		{"price_band_percentage", priceBandPercent, &spec.PriceBandPercent},
+	}
+
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+
+		d, err := decimal.NewFromString(f.value)
+		if err != nil {
+			return SymbolSpec{}, fmt.Errorf("invalid %s %q: %w", f.name, f.value, err)
+		}
+		if d.IsNegative() {
+			return SymbolSpec{}, fmt.Errorf("%s must not be negative", f.name)
+		}
+		*f.dest = d
+	}
+
+	if spec.MaxQuantity.IsPositive() && spec.MaxQuantity.LessThan(spec.MinQuantity) {
+		return SymbolSpec{}, fmt.Errorf("max_order_size must be >= min_order_size")
+	}
+
+	return spec, nil
+}
+
+// ValidateOrder checks an order against the symbol's trading rules
+func (s SymbolSpec) ValidateOrder(order *Order) error {
+	if order.Price.IsPositive() {
+		if err := s.checkPrice(order.Price); err != nil {
+			return err
+		}
+	}
+
+	if order.StopPrice.IsPositive() && order.OrderType != OrderTypeTrailingStop {
+		if err := s.checkPrice(order.StopPrice); err != nil {
+			return err
+		}
+	}
+
+	if err := s.checkQuantity(order.Quantity); err != nil {
+		return err
+	}
+
+	// Notional needs a price: MARKET orders are not checked here
+	refPrice := order.Price
+	if refPrice.IsZero() {
+		refPrice = order.StopPrice
+	}
+	return s.checkNotional(refPrice, order.Quantity)
+}
+
+// checkPrice enforces the tick size
+func (s SymbolSpec) checkPrice(price decimal.Decimal) error {
+	if s.TickSize.IsPositive() && !price.Mod(s.TickSize).IsZero() {
+		return newOrderError(ErrCodeInvalidTickSize,
+			"price %s is not a multiple of tick size %s", price, s.TickSize)
+	}
+	return nil
+}
+
+// checkQuantity enforces the step size and min/max order size
+func (s SymbolSpec) checkQuantity(quantity decimal.Decimal) error {
+	if s.StepSize.IsPositive() && !quantity.Mod(s.StepSize).IsZero() {
+		return newOrderError(ErrCodeInvalidStepSize,
+			"quantity %s is not a multiple of step size %s", quantity, s.StepSize)
+	}
+
+	if s.MinQuantity.IsPositive() && quantity.LessThan(s.MinQuantity) {
+		return newOrderError(ErrCodeQuantityTooSmall,
+			"quantity %s is below minimum order size %s", quantity, s.MinQuantity)
+	}
+
+	if s.MaxQuantity.IsPositive() && quantity.GreaterThan(s.MaxQuantity) {
+		return newOrderError(ErrCodeQuantityTooLarge,
+			"quantity %s exceeds maximum order size %s", quantity, s.MaxQuantity)
+	}
+
+	return nil
+}
+
+// checkNotional enforces the minimum order value
+func (s SymbolSpec) checkNotional(price, quantity decimal.Decimal) error {
+	if !s.MinNotional.IsPositive() || price.IsZero() {
+		return nil
+	}
+
+	if notional := price.Mul(quantity); notional.LessThan(s.MinNotional) {
+		return newOrderError(ErrCodeNotionalTooSmall,
+			"order value %s is below minimum order value %s", notional, s.MinNotional)
+	}
+	return nil
+}
+
+// SetSymbolSpec sets the trading rules for a symbol's order book. A
+// fixed-point book keeps its scale; rules finer than it are checked on
+// decimals.
+func (me *MatchingEngine) SetSymbolSpec(symbol string, spec SymbolSpec) error {
+	ob, ok := me.GetOrderBook(symbol)
+	if !ok {
+		return errUnknownSymbol(symbol)
+	}
+
+	ob.mu.Lock()
+	ob.Spec = spec
+	if ob.fx != nil {
+		ob.fxRules = ob.fx.rules(spec)
+	}
+	ob.mu.Unlock()
+	return nil
+}
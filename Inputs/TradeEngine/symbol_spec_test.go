@@ -0,0 +1,111 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - SYMBOL TRADING RULES TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSpecEngine(t *testing.T) *MatchingEngine {
+	spec, err := NewSymbolSpec("0.01", "0.0001", "0.001", "100", "10", "")
+	require.NoError(t, err)
+
+	me := newTestEngine()
+	me.SetSymbolSpec("BTC/USDT", spec)
+	return me
+}
+
+func TestNewSymbolSpec(t *testing.T) {
+	spec, err := NewSymbolSpec("0.01", "", "0.0001", "100", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "0.01", spec.TickSize.String())
+	assert.True(t, spec.StepSize.IsZero())
+	assert.True(t, spec.MinNotional.IsZero())
+
+	_, err = NewSymbolSpec("abc", "", "", "", "", "")
+	assert.Error(t, err)
+
+	_, err = NewSymbolSpec("", "", "10", "1", "", "")
+	assert.Error(t, err)
+}
+
+func TestSymbolSpec_Rejections(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity string
+		price    string
+		code     ErrorCode
+	}{
+		{"tick size", "1.0", "50000.005", ErrCodeInvalidTickSize},
+		{"step size", "1.00005", "50000", ErrCodeInvalidStepSize},
+		{"min quantity", "0.0005", "50000", ErrCodeQuantityTooSmall},
+		{"max quantity", "101", "50000", ErrCodeQuantityTooLarge},
+		{"min notional", "0.001", "5000", ErrCodeNotionalTooSmall},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			me := newTestSpecEngine(t)
+
+			order := newTestOrder(SideBuy, OrderTypeLimit, tt.quantity, tt.price)
+			_, err := me.PlaceOrder(order)
+
+			require.Error(t, err)
+			assert.Equal(t, tt.code, ErrorCodeOf(err))
+			assert.Equal(t, OrderStatusRejected, order.Status)
+
+			ob := me.GetOrCreateOrderBook("BTC/USDT")
+			assert.Equal(t, 0, len(ob.Orders))
+		})
+	}
+}
+
+func TestSymbolSpec_AcceptsValidOrders(t *testing.T) {
+	me := newTestSpecEngine(t)
+
+	sell := newTestOrder(SideSell, OrderTypeLimit, "0.5", "50000.01")
+	_, err := me.PlaceOrder(sell)
+	require.NoError(t, err)
+
+	// Market orders have no price: only quantity rules apply
+	buy := newTestMarketOrder(SideBuy, "0.001")
+	trades, err := me.PlaceOrder(buy)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(trades))
+
+	buy = newTestMarketOrder(SideBuy, "0.00015")
+	_, err = me.PlaceOrder(buy)
+	assert.Equal(t, ErrCodeInvalidStepSize, ErrorCodeOf(err))
+}
+
+func TestSymbolSpec_StopPriceTickSize(t *testing.T) {
+	me := newTestSpecEngine(t)
+
+	stop := newTestStopOrder(SideSell, "1.0", "49000.001")
+	_, err := me.PlaceOrder(stop)
+	assert.Equal(t, ErrCodeInvalidTickSize, ErrorCodeOf(err))
+}
+
+func TestSymbolSpec_AmendOrder(t *testing.T) {
+	me := newTestSpecEngine(t)
+
+	sell := newTestOrder(SideSell, OrderTypeLimit, "1.0", "50000")
+	_, err := me.PlaceOrder(sell)
+	require.NoError(t, err)
+
+	_, err = me.AmendOrder(sell.OrderID, sell.Symbol, sell.UserID, decimal.RequireFromString("50000.001"), decimal.Zero)
+	assert.Equal(t, ErrCodeInvalidTickSize, ErrorCodeOf(err))
+
+	_, err = me.AmendOrder(sell.OrderID, sell.Symbol, sell.UserID, decimal.Zero, decimal.NewFromInt(200))
+	assert.Equal(t, ErrCodeQuantityTooLarge, ErrorCodeOf(err))
+
+	// Rejected amendments leave the order untouched
+	assert.Equal(t, "50000", sell.Price.String())
+	assert.Equal(t, "1", sell.Quantity.String())
+}
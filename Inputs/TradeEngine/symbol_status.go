@@ -0,0 +1,270 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - SYMBOL LIFECYCLE
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Trading Status)
+// Description: ACTIVE / HALTED / MAINTENANCE / DELISTED state machine per
+//              OrderBook, deciding which orders a symbol accepts (FR-014)
+// ============================================================================
+
+package matching
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ============================================================================
+// TYPES
+// ============================================================================
+
+type SymbolStatus string
+
+const (
+	SymbolStatusActive      SymbolStatus = "ACTIVE"
+	SymbolStatusHalted      SymbolStatus = "HALTED"
+	SymbolStatusMaintenance SymbolStatus = "MAINTENANCE"
+	SymbolStatusDelisted    SymbolStatus = "DELISTED"
+)
+
+// OrderAcceptance describes which commands a symbol status accepts
+type OrderAcceptance string
+
+const (
+	AcceptAll        OrderAcceptance = "ALL"         // Normal trading
+	AcceptPostOnly   OrderAcceptance = "POST_ONLY"   // Non-crossing limit orders and cancels
+	AcceptCancelOnly OrderAcceptance = "CANCEL_ONLY" // Cancels only
+	AcceptNone       OrderAcceptance = "NONE"        // Nothing
+)
+
+// symbolStatusRules lists, per status, the accepted orders and the statuses
+// it may move to. DELISTED is terminal.
+var symbolStatusRules = map[SymbolStatus]struct {
+	accepts OrderAcceptance
+	next    []SymbolStatus
+}{
+	SymbolStatusActive: {
+		accepts: AcceptAll,
+		next:    []SymbolStatus{SymbolStatusHalted, SymbolStatusMaintenance, SymbolStatusDelisted},
+	},
+	SymbolStatusHalted: {
+		accepts: AcceptCancelOnly,
+		next:    []SymbolStatus{SymbolStatusActive, SymbolStatusMaintenance, SymbolStatusDelisted},
+	},
+	SymbolStatusMaintenance: {
+		// The book can be rebuilt ahead of re-opening, without matching
+		accepts: AcceptPostOnly,
+		next:    []SymbolStatus{SymbolStatusActive, SymbolStatusHalted, SymbolStatusDelisted},
+	},
+	SymbolStatusDelisted: {
+		accepts: AcceptNone,
+	},
+}
+
+// IsValid reports whether s is a known symbol status
+func (s SymbolStatus) IsValid() bool {
+	_, ok := symbolStatusRules[s]
+	return ok
+}
+
+// Accepts returns which orders a symbol in status s accepts
+func (s SymbolStatus) Accepts() OrderAcceptance {
+	if rules, ok := symbolStatusRules[s]; ok {
+		return rules.accepts
+	}
+	return AcceptNone
+}
+
+// CanTransitionTo reports whether s may move to next
+func (s SymbolStatus) CanTransitionTo(next SymbolStatus) bool {
+	for _, allowed := range symbolStatusRules[s].next {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ============================================================================
+// ENGINE INTEGRATION
+// ============================================================================
+
+// GetSymbolStatus returns the trading status of a symbol
+func (me *MatchingEngine) GetSymbolStatus(symbol string) (SymbolStatus, bool) {
+	ob, ok := me.GetOrderBook(symbol)
+	if !ok {
+		return "", false
+	}
+
+	var status SymbolStatus
+	err := ob.do(func() error {
+		status = ob.Status
+		return nil
+	})
+	return status, err == nil
+}
+
+// SetSymbolStatus moves a symbol to a new trading status (admin action).
+// Leaving HALTED lifts any circuit breaker halt; DELISTED cancels all
+// resting and stop orders.
+func (me *MatchingEngine) SetSymbolStatus(symbol string, status SymbolStatus, reason string) error {
+	return me.setSymbolStatusAt(symbol, status, reason, me.clock.Now())
+}
+
+func (me *MatchingEngine) setSymbolStatusAt(symbol string, status SymbolStatus, reason string, at time.Time) error {
+	if !status.IsValid() {
+		return fmt.Errorf("invalid symbol status: %s", status)
+	}
+
+	ob, ok := me.GetOrderBook(symbol)
+	if !ok {
+		return errUnknownSymbol(symbol)
+	}
+
+	return ob.doAt(at, func() error {
+		entry := &JournalEntry{Type: JournalSymbolStatus, Symbol: symbol, Status: status, Reason: reason}
+		if err := me.record(ob, entry); err != nil {
+			return err
+		}
+
+		if !ob.Status.CanTransitionTo(status) {
+			return fmt.Errorf("cannot change %s status from %s to %s", symbol, ob.Status, status)
+		}
+
+		if ob.Status == SymbolStatusHalted {
+			ob.Breaker.reset()
+		}
+
+		me.setSymbolStatus(ob, status, reason)
+
+		if status == SymbolStatusDelisted {
+			me.cancelAllOrders(ob, StatusReasonDelisted, nil)
+		}
+		return nil
+	})
+}
+
+// setSymbolStatus changes the status and reports it. Runs on the book's
+// goroutine.
+func (me *MatchingEngine) setSymbolStatus(ob *OrderBook, status SymbolStatus, reason string) {
+	old := ob.Status
+	ob.Status = status
+
+	me.publish(ob, EventSymbolStatusChanged, func(h EventHeader) Event {
+		return &SymbolStatusChangedEvent{EventHeader: h, OldStatus: old, NewStatus: status, Reason: reason}
+	})
+}
+
+// checkOrderAccepted rejects a new order the symbol's status does not accept.
+// A nil order stands for an amend, which needs normal trading.
+// Runs on the book's goroutine.
+func (me *MatchingEngine) checkOrderAccepted(ob *OrderBook, order *Order, now time.Time) error {
+	me.resumeIfExpired(ob, now)
+
+	switch ob.Status.Accepts() {
+	case AcceptAll:
+		return nil
+
+	case AcceptPostOnly:
+		if order != nil && order.OrderType == OrderTypeLimit && !ob.wouldCross(order) {
+			return nil
+		}
+		return newOrderError(ErrCodeSymbolNotTrading,
+			"%s is in %s: only non-crossing limit orders are accepted", ob.Symbol, ob.Status)
+	}
+
+	if ob.Status == SymbolStatusHalted {
+		if ob.Breaker.halted {
+			return newOrderError(ErrCodeSymbolHalted,
+				"trading on %s is halted until %s", ob.Symbol, ob.Breaker.haltedUntil.Format(time.RFC3339))
+		}
+		return newOrderError(ErrCodeSymbolHalted, "trading on %s is halted", ob.Symbol)
+	}
+	return newOrderError(ErrCodeSymbolNotTrading, "%s is %s", ob.Symbol, ob.Status)
+}
+
+// checkCancelAccepted rejects cancels on a symbol accepting nothing.
+// Runs on the book's goroutine.
+func (me *MatchingEngine) checkCancelAccepted(ob *OrderBook) error {
+	if ob.Status.Accepts() == AcceptNone {
+		return newOrderError(ErrCodeSymbolNotTrading, "%s is %s", ob.Symbol, ob.Status)
+	}
+	return nil
+}
+
+// wouldCross reports whether a limit order would trade on arrival
+func (ob *OrderBook) wouldCross(order *Order) bool {
+	if order.Side == SideBuy {
+		bestAsk := ob.GetBestAsk()
+		return !bestAsk.IsZero() && order.Price.GreaterThanOrEqual(bestAsk)
+	}
+
+	bestBid := ob.GetBestBid()
+	return !bestBid.IsZero() && order.Price.LessThanOrEqual(bestBid)
+}
+
+// cancelAllOrders cancels the resting and stop orders of the book that match
+// accepts (all of them when nil) and returns them in the order cancelled.
+// Runs on the book's goroutine.
+func (me *MatchingEngine) cancelAllOrders(ob *OrderBook, reason StatusReason, match func(*Order) bool) []*Order {
+	if match == nil {
+		match = func(*Order) bool { return true }
+	}
+
+	ob.mu.Lock()
+	orders := make([]*Order, 0, len(ob.Orders)+len(ob.StopOrders))
+	for _, order := range ob.Orders {
+		if match(order) {
+			orders = append(orders, order)
+		}
+	}
+	ob.mu.Unlock()
+
+	// Arrival order, so a replay reports the same sequence of updates
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].CreatedAt.Equal(orders[j].CreatedAt) {
+			return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+		}
+		return orders[i].OrderID < orders[j].OrderID
+	})
+
+	cancelled := make([]*Order, 0, len(orders))
+	for _, order := range orders {
+		if err := ob.RemoveOrder(order.OrderID); err != nil {
+			continue
+		}
+		cancelWithReason(order, reason, ob.now)
+
+		me.emitOrderCancelled(ob, order)
+		cancelled = append(cancelled, order)
+	}
+
+	ob.mu.Lock()
+	var stops []*Order
+	kept := make([]*Order, 0, len(ob.StopOrders))
+	for _, order := range ob.StopOrders {
+		if match(order) {
+			stops = append(stops, order)
+			ob.stops.remove(order)
+		} else {
+			kept = append(kept, order)
+		}
+	}
+	ob.StopOrders = kept
+	ob.mu.Unlock()
+
+	for _, order := range stops {
+		cancelWithReason(order, reason, ob.now)
+
+		me.emitOrderCancelled(ob, order)
+		cancelled = append(cancelled, order)
+	}
+	return cancelled
+}
+
+func cancelWithReason(order *Order, reason StatusReason, at time.Time) {
+	order.Status = OrderStatusCancelled
+	order.StatusReason = reason
+	order.UpdatedAt = at
+}
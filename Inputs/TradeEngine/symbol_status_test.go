@@ -0,0 +1,181 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - SYMBOL LIFECYCLE TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymbolStatus_Transitions(t *testing.T) {
+	tests := []struct {
+		from, to SymbolStatus
+		allowed  bool
+	}{
+		{SymbolStatusActive, SymbolStatusHalted, true},
+		{SymbolStatusActive, SymbolStatusMaintenance, true},
+		{SymbolStatusActive, SymbolStatusDelisted, true},
+		{SymbolStatusActive, SymbolStatusActive, false},
+		{SymbolStatusHalted, SymbolStatusActive, true},
+		{SymbolStatusMaintenance, SymbolStatusHalted, true},
+		{SymbolStatusDelisted, SymbolStatusActive, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, tt.from.CanTransitionTo(tt.to), "%s -> %s", tt.from, tt.to)
+	}
+
+	assert.Equal(t, AcceptAll, SymbolStatusActive.Accepts())
+	assert.Equal(t, AcceptCancelOnly, SymbolStatusHalted.Accepts())
+	assert.Equal(t, AcceptPostOnly, SymbolStatusMaintenance.Accepts())
+	assert.Equal(t, AcceptNone, SymbolStatusDelisted.Accepts())
+	assert.False(t, SymbolStatus("CLOSED").IsValid())
+}
+
+func TestMatchingEngine_SetSymbolStatus(t *testing.T) {
+	me := newTestEngine()
+	me.GetOrCreateOrderBook("BTC/USDT")
+
+	recorder := recordEvents(t, me)
+
+	assert.Error(t, me.SetSymbolStatus("DOGE/USDT", SymbolStatusHalted, "test"))
+	assert.Error(t, me.SetSymbolStatus("BTC/USDT", "CLOSED", "test"))
+	assert.Error(t, me.SetSymbolStatus("BTC/USDT", SymbolStatusActive, "already active"))
+
+	require.NoError(t, me.SetSymbolStatus("BTC/USDT", SymbolStatusMaintenance, "upgrade"))
+	status, ok := me.GetSymbolStatus("BTC/USDT")
+	assert.True(t, ok)
+	assert.Equal(t, SymbolStatusMaintenance, status)
+
+	var events []*SymbolStatusChangedEvent
+	for _, event := range recorder.all() {
+		if e, ok := event.(*SymbolStatusChangedEvent); ok {
+			events = append(events, e)
+		}
+	}
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, SymbolStatusActive, events[0].OldStatus)
+	assert.Equal(t, SymbolStatusMaintenance, events[0].NewStatus)
+	assert.Equal(t, "upgrade", events[0].Reason)
+}
+
+func TestMatchingEngine_SymbolStatus_HaltedIsCancelOnly(t *testing.T) {
+	me := newTestEngine()
+	resting := newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000")
+	_, err := me.PlaceOrder(resting)
+	require.NoError(t, err)
+
+	require.NoError(t, me.SetSymbolStatus("BTC/USDT", SymbolStatusHalted, "incident"))
+
+	_, err = me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49000"))
+	assert.Equal(t, ErrCodeSymbolHalted, ErrorCodeOf(err))
+
+	_, err = me.AmendOrder(resting.OrderID, resting.Symbol, resting.UserID, decimal.NewFromInt(49500), decimal.Zero)
+	assert.Equal(t, ErrCodeSymbolHalted, ErrorCodeOf(err))
+
+	assert.NoError(t, me.CancelOrder(resting.OrderID, resting.Symbol, resting.UserID))
+
+	// A manual halt has no cooldown: it stays until an admin resumes
+	me.CheckCircuitBreakers()
+	assert.True(t, me.IsHalted("BTC/USDT"))
+
+	require.NoError(t, me.SetSymbolStatus("BTC/USDT", SymbolStatusActive, "resolved"))
+	_, err = me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49000"))
+	assert.NoError(t, err)
+}
+
+func TestMatchingEngine_SymbolStatus_MaintenanceIsPostOnly(t *testing.T) {
+	me := newTestEngine()
+	me.PlaceOrder(newTestOrder(SideSell, OrderTypeLimit, "1.0", "51000"))
+
+	require.NoError(t, me.SetSymbolStatus("BTC/USDT", SymbolStatusMaintenance, "upgrade"))
+
+	// Non-crossing limit orders rest in the book
+	bid := newTestOrder(SideBuy, OrderTypeLimit, "1.0", "50000")
+	trades, err := me.PlaceOrder(bid)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(trades))
+	assert.Equal(t, OrderStatusOpen, bid.Status)
+
+	// Crossing limit and market orders are rejected
+	_, err = me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "51000"))
+	assert.Equal(t, ErrCodeSymbolNotTrading, ErrorCodeOf(err))
+
+	_, err = me.PlaceOrder(newTestMarketOrder(SideBuy, "1.0"))
+	assert.Equal(t, ErrCodeSymbolNotTrading, ErrorCodeOf(err))
+
+	// Circuit breaker can't halt a symbol under maintenance
+	assert.Error(t, me.TriggerCircuitBreaker("BTC/USDT", 0, "manual"))
+}
+
+func TestMatchingEngine_SymbolStatus_DelistCancelsOrders(t *testing.T) {
+	me := newTestEngine()
+	tradeAt(t, me, "50000")
+
+	bid := newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49000")
+	ask := newTestOrder(SideSell, OrderTypeLimit, "1.0", "51000")
+	stop := newTestStopOrder(SideSell, "1.0", "48000")
+	for _, order := range []*Order{bid, ask, stop} {
+		_, err := me.PlaceOrder(order)
+		require.NoError(t, err)
+	}
+
+	events := recordEvents(t, me)
+	require.NoError(t, me.SetSymbolStatus("BTC/USDT", SymbolStatusDelisted, "delisting"))
+
+	var cancelled []string
+	for _, event := range events.all() {
+		if e, ok := event.(*OrderCancelledEvent); ok {
+			cancelled = append(cancelled, e.Order.OrderID)
+		}
+	}
+
+	assert.ElementsMatch(t, []string{bid.OrderID, ask.OrderID, stop.OrderID}, cancelled)
+	for _, order := range []*Order{bid, ask, stop} {
+		assert.Equal(t, OrderStatusCancelled, order.Status)
+		assert.Equal(t, StatusReasonDelisted, order.StatusReason)
+	}
+
+	ob, _ := me.GetOrderBook("BTC/USDT")
+	assert.Equal(t, 0, len(ob.Orders))
+	assert.Equal(t, 0, len(ob.StopOrders))
+	assert.True(t, ob.GetBestBid().IsZero())
+
+	// Nothing is accepted any more, and DELISTED is terminal
+	_, err := me.PlaceOrder(newTestOrder(SideBuy, OrderTypeLimit, "1.0", "49000"))
+	assert.Equal(t, ErrCodeSymbolNotTrading, ErrorCodeOf(err))
+	assert.Error(t, me.CancelOrder(bid.OrderID, bid.Symbol, bid.UserID))
+	assert.Error(t, me.SetSymbolStatus("BTC/USDT", SymbolStatusActive, "relist"))
+}
+
+func TestMatchingEngine_UnknownSymbol(t *testing.T) {
+	me := newTestEngine()
+
+	// Neither lookups nor commands create books
+	_, ok := me.GetOrderBook("DOGE/USDT")
+	assert.False(t, ok)
+	assert.Nil(t, me.GetOrderBookSnapshot("DOGE/USDT", 10))
+	assert.Equal(t, ErrCodeUnknownSymbol, ErrorCodeOf(me.CancelOrder("order-1", "DOGE/USDT", "")))
+	_, err := me.AmendOrder("order-1", "DOGE/USDT", "", decimal.NewFromInt(1), decimal.Zero)
+	assert.Equal(t, ErrCodeUnknownSymbol, ErrorCodeOf(err))
+	_, err = me.CancelAllOrders("alice", "DOGE/USDT", "")
+	assert.Equal(t, ErrCodeUnknownSymbol, ErrorCodeOf(err))
+
+	order := newTestOrder(SideBuy, OrderTypeLimit, "1.0", "0.1")
+	order.Symbol = "DOGE/USDT"
+	_, err = me.PlaceOrder(order)
+	assert.Equal(t, ErrCodeUnknownSymbol, ErrorCodeOf(err))
+	assert.Equal(t, OrderStatusRejected, order.Status)
+
+	assert.Equal(t, ErrCodeUnknownSymbol, ErrorCodeOf(me.SetSymbolSpec("DOGE/USDT", SymbolSpec{})))
+	assert.Equal(t, ErrCodeUnknownSymbol, ErrorCodeOf(me.SetCircuitBreaker("DOGE/USDT", CircuitBreakerConfig{})))
+	assert.Equal(t, ErrCodeUnknownSymbol, ErrorCodeOf(me.SetSymbolStatus("DOGE/USDT", SymbolStatusHalted, "test")))
+
+	_, ok = me.GetOrderBook("DOGE/USDT")
+	assert.False(t, ok)
+}
@@ -0,0 +1,278 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - TRADE STORE
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Matching Engine (Trade History & Public Trade Tape)
+// Description: Trades fed by the engine's trade events: a bounded ring of
+//              recent trades per symbol for the public tape, and every
+//              user's fills indexed by execution time for history queries
+// ============================================================================
+
+package matching
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Trade store sizes
+const (
+	DefaultRecentTrades    = 1000 // Public trades kept per symbol
+	DefaultTradeQueryLimit = 50
+	MaxTradeQueryLimit     = 100
+)
+
+// UserTrade is a trade from the point of view of one of its users. A
+// self-trade is a fill of both sides, so its user has one of each.
+type UserTrade struct {
+	Trade *Trade
+	Side  Side // The user's side
+}
+
+// TradeQuery selects the trades of one user. Zero fields do not filter.
+type TradeQuery struct {
+	UserID   string
+	Symbol   string
+	From, To time.Time // ExecutedAt range, To exclusive
+	Cursor   string    // NextCursor of the previous page; "" for the first
+	Limit    int       // 0 = DefaultTradeQueryLimit
+}
+
+// TradePage is one page of a query, newest trade first
+type TradePage struct {
+	Trades     []UserTrade
+	NextCursor string // "" on the last page
+}
+
+// tradeRing keeps the latest trades of a symbol
+type tradeRing struct {
+	buf  []*Trade
+	next int // Slot of the next trade
+	full bool
+}
+
+func (r *tradeRing) add(trade *Trade) {
+	r.buf[r.next] = trade
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// latest returns up to limit trades, newest first
+func (r *tradeRing) latest(limit int) []*Trade {
+	n := r.next
+	if r.full {
+		n = len(r.buf)
+	}
+	if limit < n {
+		n = limit
+	}
+
+	trades := make([]*Trade, 0, n)
+	for i := 1; i <= n; i++ {
+		trades = append(trades, r.buf[(r.next-i+len(r.buf))%len(r.buf)])
+	}
+	return trades
+}
+
+// TradeStore keeps the trades the engine publishes. Trades stay in memory
+// until the database takes over history; only the public tape is bounded.
+type TradeStore struct {
+	sub    *Subscription
+	recent int
+
+	mu     sync.RWMutex
+	rings  map[string]*tradeRing
+	trades map[string]*Trade
+	byUser map[string][]UserTrade // Oldest first, see tradeBefore
+}
+
+// NewTradeStore subscribes a store to the engine's trade events, keeping the
+// latest recent trades of every symbol (0 = DefaultRecentTrades). Created
+// before RecoverFromJournal, it also keeps the trades the replay publishes.
+func NewTradeStore(me *MatchingEngine, recent int) (*TradeStore, error) {
+	if recent < 0 {
+		return nil, fmt.Errorf("recent trades must not be negative, got %d", recent)
+	}
+	if recent == 0 {
+		recent = DefaultRecentTrades
+	}
+
+	s := &TradeStore{
+		recent: recent,
+		rings:  make(map[string]*tradeRing),
+		trades: make(map[string]*Trade),
+		byUser: make(map[string][]UserTrade),
+	}
+
+	sub, err := me.Subscribe(s, SubscriptionConfig{Backpressure: BackpressureBlock})
+	if err != nil {
+		return nil, err
+	}
+	s.sub = sub
+
+	return s, nil
+}
+
+// HandleEvent records trade events. Runs on the subscription's goroutine.
+func (s *TradeStore) HandleEvent(event Event) {
+	te, ok := event.(*TradeEvent)
+	if !ok {
+		return
+	}
+	trade := te.Trade
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ring, ok := s.rings[trade.Symbol]
+	if !ok {
+		ring = &tradeRing{buf: make([]*Trade, s.recent)}
+		s.rings[trade.Symbol] = ring
+	}
+	ring.add(trade)
+
+	s.trades[trade.TradeID] = trade
+	s.index(trade.BuyerUserID, UserTrade{Trade: trade, Side: SideBuy})
+	s.index(trade.SellerUserID, UserTrade{Trade: trade, Side: SideSell})
+}
+
+// index adds a fill to its user's trades. Books execute concurrently, so a
+// trade may arrive after a later one of another symbol. Called under mu.
+func (s *TradeStore) index(userID string, ut UserTrade) {
+	trades := append(s.byUser[userID], ut)
+	i := len(trades) - 1
+	for ; i > 0 && tradeBefore(ut, trades[i-1]); i-- {
+		trades[i] = trades[i-1]
+	}
+	trades[i] = ut
+	s.byUser[userID] = trades
+}
+
+// tradeBefore orders fills by execution time, then global sequence, then
+// side (the two fills of a self-trade)
+func tradeBefore(a, b UserTrade) bool {
+	if !a.Trade.ExecutedAt.Equal(b.Trade.ExecutedAt) {
+		return a.Trade.ExecutedAt.Before(b.Trade.ExecutedAt)
+	}
+	if a.Trade.GlobalSequence != b.Trade.GlobalSequence {
+		return a.Trade.GlobalSequence < b.Trade.GlobalSequence
+	}
+	return a.Side == SideBuy && b.Side == SideSell
+}
+
+// Get returns a trade by ID. Trades published before the call are included.
+func (s *TradeStore) Get(tradeID string) (*Trade, bool) {
+	s.sub.Flush()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	trade, ok := s.trades[tradeID]
+	return trade, ok
+}
+
+// Recent returns up to limit of the latest trades of symbol, newest first.
+// Trades published before the call are included.
+func (s *TradeStore) Recent(symbol string, limit int) []*Trade {
+	s.sub.Flush()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ring, ok := s.rings[symbol]
+	if !ok {
+		return make([]*Trade, 0)
+	}
+	return ring.latest(limit)
+}
+
+// Query returns a page of a user's trades, newest first. Trades published
+// before the call are included.
+func (s *TradeStore) Query(q TradeQuery) (TradePage, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultTradeQueryLimit
+	}
+	if limit > MaxTradeQueryLimit {
+		limit = MaxTradeQueryLimit
+	}
+
+	s.sub.Flush()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	trades := s.byUser[q.UserID]
+
+	// Start below the cursor's fill, or below To
+	end := len(trades)
+	if q.Cursor != "" {
+		cursor, err := parseTradeCursor(q.Cursor)
+		if err != nil {
+			return TradePage{}, err
+		}
+		end = sort.Search(len(trades), func(i int) bool { return !tradeBefore(trades[i], cursor) })
+	}
+	if !q.To.IsZero() {
+		if to := sort.Search(len(trades), func(i int) bool { return !trades[i].Trade.ExecutedAt.Before(q.To) }); to < end {
+			end = to
+		}
+	}
+
+	page := TradePage{Trades: make([]UserTrade, 0)}
+	for i := end - 1; i >= 0; i-- {
+		ut := trades[i]
+		if !q.From.IsZero() && ut.Trade.ExecutedAt.Before(q.From) {
+			break
+		}
+		if q.Symbol != "" && ut.Trade.Symbol != q.Symbol {
+			continue
+		}
+		if len(page.Trades) == limit {
+			page.NextCursor = tradeCursor(page.Trades[limit-1])
+			break
+		}
+		page.Trades = append(page.Trades, ut)
+	}
+	return page, nil
+}
+
+// tradeCursor encodes the sort key of the last fill of a page
+func tradeCursor(ut UserTrade) string {
+	return fmt.Sprintf("%d_%d_%s", ut.Trade.ExecutedAt.UnixNano(), ut.Trade.GlobalSequence, ut.Side)
+}
+
+// parseTradeCursor decodes a cursor into a fill with its sort key
+func parseTradeCursor(cursor string) (UserTrade, error) {
+	parts := strings.Split(cursor, "_")
+	if len(parts) != 3 {
+		return UserTrade{}, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return UserTrade{}, ErrInvalidCursor
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return UserTrade{}, ErrInvalidCursor
+	}
+	side := Side(parts[2])
+	if side != SideBuy && side != SideSell {
+		return UserTrade{}, ErrInvalidCursor
+	}
+
+	trade := &Trade{ExecutedAt: time.Unix(0, nanos)}
+	trade.GlobalSequence = seq
+	return UserTrade{Trade: trade, Side: side}, nil
+}
+
+// Close unsubscribes the store
+func (s *TradeStore) Close() {
+	s.sub.Close()
+}
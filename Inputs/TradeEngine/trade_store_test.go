@@ -0,0 +1,144 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - TRADE STORE TESTS
+// ============================================================================
+
+package matching
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTradeStore(t *testing.T, me *MatchingEngine, recent int) *TradeStore {
+	s, err := NewTradeStore(me, recent)
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// userTradeAt makes seller sell 1.0 of symbol to buyer at price
+func userTradeAt(t *testing.T, me *MatchingEngine, seller, buyer, symbol, price string) *Trade {
+	_, err := me.PlaceOrder(userOrder(seller, symbol, SideSell, "1.0", price))
+	require.NoError(t, err)
+	trades, err := me.PlaceOrder(userOrder(buyer, symbol, SideBuy, "1.0", price))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trades))
+	return trades[0]
+}
+
+func TestTradeStore_Recent(t *testing.T) {
+	me := newTestEngine()
+	defer me.Close()
+	s := newTestTradeStore(t, me, 3)
+
+	var ids []string
+	for _, price := range []string{"50000", "50100", "50200", "50300", "50400"} {
+		ids = append(ids, userTradeAt(t, me, "alice", "bob", "BTC/USDT", price).TradeID)
+	}
+	userTradeAt(t, me, "alice", "bob", "ETH/USDT", "3000")
+
+	tradeIDs := func(trades []*Trade) []string {
+		ids := make([]string, 0)
+		for _, trade := range trades {
+			ids = append(ids, trade.TradeID)
+		}
+		return ids
+	}
+
+	// Only the latest trades are kept, newest first
+	assert.Equal(t, []string{ids[4], ids[3], ids[2]}, tradeIDs(s.Recent("BTC/USDT", 10)))
+	assert.Equal(t, []string{ids[4], ids[3]}, tradeIDs(s.Recent("BTC/USDT", 2)))
+	assert.Equal(t, 1, len(s.Recent("ETH/USDT", 10)))
+	assert.Empty(t, s.Recent("DOGE/USDT", 10))
+
+	// Trades that left the tape can still be looked up
+	trade, ok := s.Get(ids[0])
+	require.True(t, ok)
+	assert.Equal(t, "50000", trade.Price.String())
+	_, ok = s.Get("no-such-trade")
+	assert.False(t, ok)
+
+	_, err := NewTradeStore(me, -1)
+	assert.Error(t, err)
+}
+
+func TestTradeStore_Query(t *testing.T) {
+	me, clock := newTestClockEngine()
+	s := newTestTradeStore(t, me, 0)
+
+	// alice: BTC sell 1, ETH buy 2, BTC sell 3, BTC self-trade 4 (newest)
+	var trades []*Trade
+	for _, tt := range []struct{ seller, buyer, symbol string }{
+		{"alice", "bob", "BTC/USDT"},
+		{"bob", "alice", "ETH/USDT"},
+		{"alice", "bob", "BTC/USDT"},
+		{"alice", "alice", "BTC/USDT"},
+	} {
+		clock.Advance(time.Second)
+		trades = append(trades, userTradeAt(t, me, tt.seller, tt.buyer, tt.symbol, "50000"))
+	}
+
+	fills := func(page TradePage) []string {
+		fills := make([]string, 0)
+		for _, ut := range page.Trades {
+			fills = append(fills, ut.Trade.TradeID+" "+string(ut.Side))
+		}
+		return fills
+	}
+
+	// Newest first, paged with the cursor; the self-trade is a fill of both sides
+	page, err := s.Query(TradeQuery{UserID: "alice", Limit: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		trades[3].TradeID + " SELL",
+		trades[3].TradeID + " BUY",
+		trades[2].TradeID + " SELL",
+	}, fills(page))
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = s.Query(TradeQuery{UserID: "alice", Limit: 3, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{trades[1].TradeID + " BUY", trades[0].TradeID + " SELL"}, fills(page))
+	assert.Empty(t, page.NextCursor)
+
+	// Filters
+	page, err = s.Query(TradeQuery{UserID: "alice", Symbol: "ETH/USDT"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{trades[1].TradeID + " BUY"}, fills(page))
+
+	page, err = s.Query(TradeQuery{UserID: "alice", From: testEpoch.Add(2 * time.Second), To: testEpoch.Add(4 * time.Second)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{trades[2].TradeID + " SELL", trades[1].TradeID + " BUY"}, fills(page))
+
+	page, err = s.Query(TradeQuery{UserID: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, len(page.Trades))
+
+	page, err = s.Query(TradeQuery{UserID: "carol"})
+	require.NoError(t, err)
+	assert.Empty(t, page.Trades)
+
+	for _, cursor := range []string{"abc", "1_2", "1_2_HOLD", "x_2_BUY"} {
+		_, err = s.Query(TradeQuery{UserID: "alice", Cursor: cursor})
+		assert.Equal(t, ErrInvalidCursor, err, cursor)
+	}
+}
+
+func TestTradeStore_IndexesByExecutionTime(t *testing.T) {
+	s := &TradeStore{byUser: make(map[string][]UserTrade)}
+
+	// A trade of a book that ran behind arrives after a later one
+	late := &Trade{TradeID: "trade-2", ExecutedAt: testEpoch.Add(2 * time.Second)}
+	early := &Trade{TradeID: "trade-1", ExecutedAt: testEpoch.Add(time.Second)}
+	for _, trade := range []*Trade{late, early} {
+		s.index("alice", UserTrade{Trade: trade, Side: SideBuy})
+	}
+
+	indexed := s.byUser["alice"]
+	require.Equal(t, 2, len(indexed))
+	assert.Equal(t, "trade-1", indexed[0].Trade.TradeID)
+	assert.Equal(t, "trade-2", indexed[1].Trade.TradeID)
+}
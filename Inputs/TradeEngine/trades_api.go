@@ -0,0 +1,152 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - TRADE API
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Trade Engine Server (REST)
+// Description: Trade history and public trade tape endpoints of
+//              trade-engine-api-spec.yaml
+// ============================================================================
+
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mytrader/trade-engine/internal/matching"
+)
+
+// Public trade tape page sizes
+const (
+	defaultRecentTradesLimit = 50
+	maxRecentTradesLimit     = 100
+)
+
+// publicTradeResponse is the PublicTradeResponse schema: a trade without
+// its users
+type publicTradeResponse struct {
+	TradeID        string    `json:"trade_id"`
+	Symbol         string    `json:"symbol"`
+	Price          string    `json:"price"`
+	Quantity       string    `json:"quantity"`
+	ExecutedAt     time.Time `json:"executed_at"`
+	IsBuyerMaker   bool      `json:"is_buyer_maker"`
+	Sequence       uint64    `json:"sequence"`
+	GlobalSequence uint64    `json:"global_sequence"`
+}
+
+func newPublicTradeResponse(trade *matching.Trade) publicTradeResponse {
+	return publicTradeResponse{
+		TradeID:        trade.TradeID,
+		Symbol:         trade.Symbol,
+		Price:          trade.Price.String(),
+		Quantity:       trade.Quantity.String(),
+		ExecutedAt:     trade.ExecutedAt,
+		IsBuyerMaker:   trade.IsBuyerMaker,
+		Sequence:       trade.Sequence,
+		GlobalSequence: trade.GlobalSequence,
+	}
+}
+
+type tradeAPI struct {
+	engine *matching.MatchingEngine
+	trades *matching.TradeStore
+}
+
+// registerTradeRoutes adds the trade and public trade tape endpoints to the
+// /api/v1 group
+func registerTradeRoutes(v1 *gin.RouterGroup, engine *matching.MatchingEngine, trades *matching.TradeStore) {
+	api := &tradeAPI{engine: engine, trades: trades}
+
+	v1.GET("/trades", api.listTrades)
+	v1.GET("/trades/:trade_id", api.getTrade)
+	v1.GET("/market-data/trades/:symbol", api.recentTrades)
+}
+
+// getTrade returns a trade of the user from their side. Other users' trades
+// are reported as not found.
+func (api *tradeAPI) getTrade(c *gin.Context) {
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+
+	trade, ok := api.trades.Get(c.Param("trade_id"))
+	if !ok || (trade.BuyerUserID != userID && trade.SellerUserID != userID) {
+		writeProblem(c, http.StatusNotFound, "not-found", "Not Found", "trade not found")
+		return
+	}
+
+	side := matching.SideBuy
+	if trade.BuyerUserID != userID {
+		side = matching.SideSell
+	}
+
+	c.JSON(http.StatusOK, newTradeResponse(trade, side))
+}
+
+// listTrades returns a page of the user's trades, newest first
+func (api *tradeAPI) listTrades(c *gin.Context) {
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+
+	q := matching.TradeQuery{
+		UserID: userID,
+		Symbol: c.Query("symbol"),
+		Cursor: c.Query("cursor"),
+	}
+	var err error
+	if q.Limit, err = parseLimit(c, matching.DefaultTradeQueryLimit, matching.MaxTradeQueryLimit); err == nil {
+		q.From, q.To, err = parseDateRange(c)
+	}
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, "invalid-request", "Invalid Request", err.Error())
+		return
+	}
+
+	page, err := api.trades.Query(q)
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, "invalid-request", "Invalid Request", err.Error())
+		return
+	}
+
+	data := make([]tradeResponse, 0, len(page.Trades))
+	for _, ut := range page.Trades {
+		data = append(data, newTradeResponse(ut.Trade, ut.Side))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+		"pagination": gin.H{
+			"limit":       q.Limit,
+			"next_cursor": page.NextCursor,
+			"has_next":    page.NextCursor != "",
+		},
+	})
+}
+
+// recentTrades returns the latest public trades of a symbol, newest first
+func (api *tradeAPI) recentTrades(c *gin.Context) {
+	symbol := c.Param("symbol")
+	if _, ok := api.engine.GetOrderBook(symbol); !ok {
+		writeProblem(c, http.StatusNotFound, "not-found", "Not Found", "unknown symbol: "+symbol)
+		return
+	}
+
+	limit, err := parseLimit(c, defaultRecentTradesLimit, maxRecentTradesLimit)
+	if err != nil {
+		writeProblem(c, http.StatusBadRequest, "invalid-request", "Invalid Request", err.Error())
+		return
+	}
+
+	trades := api.trades.Recent(symbol, limit)
+	data := make([]publicTradeResponse, 0, len(trades))
+	for _, trade := range trades {
+		data = append(data, newPublicTradeResponse(trade))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
@@ -0,0 +1,121 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - TRADE API TESTS
+// ============================================================================
+
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tradeOrders makes seller sell quantity BTC to buyer at price and returns
+// the trade ID
+func tradeOrders(t *testing.T, router *gin.Engine, seller, buyer, quantity, price string) string {
+	w, _ := placeOrder(t, router, seller, `{"symbol":"BTC/USDT","side":"SELL","order_type":"LIMIT",
+		"quantity":"`+quantity+`","price":"`+price+`"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w, bid := placeOrder(t, router, buyer, `{"symbol":"BTC/USDT","side":"BUY","order_type":"LIMIT",
+		"quantity":"`+quantity+`","price":"`+price+`"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	trades := bid["trades"].([]interface{})
+	require.Equal(t, 1, len(trades))
+	return trades[0].(map[string]interface{})["trade_id"].(string)
+}
+
+func TestTradeAPI_GetTrade(t *testing.T) {
+	router, _ := newTestAPI(t)
+	tradeID := tradeOrders(t, router, "alice", "bob", "1", "50000")
+
+	// Each user sees the trade from their side
+	for user, side := range map[string]string{"alice": "SELL", "bob": "BUY"} {
+		w, trade := request(t, router, http.MethodGet, "/api/v1/trades/"+tradeID, user, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, side, trade["side"], user)
+		assert.Equal(t, "50000", trade["price"])
+	}
+
+	w, problem := request(t, router, http.MethodGet, "/api/v1/trades/"+tradeID, "carol", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, problemTypeBase+"not-found", problem["type"])
+
+	w, _ = request(t, router, http.MethodGet, "/api/v1/trades/"+tradeID, "", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestTradeAPI_ListTrades(t *testing.T) {
+	router, _ := newTestAPI(t)
+
+	var ids []string
+	for _, price := range []string{"50000", "50100", "50200"} {
+		ids = append(ids, tradeOrders(t, router, "alice", "bob", "1", price))
+	}
+
+	list := func(user, query string) (int, []string, map[string]interface{}) {
+		w, resp := request(t, router, http.MethodGet, "/api/v1/trades"+query, user, "")
+		if w.Code != http.StatusOK {
+			return w.Code, nil, resp
+		}
+		var got []string
+		for _, trade := range resp["data"].([]interface{}) {
+			got = append(got, trade.(map[string]interface{})["trade_id"].(string))
+		}
+		return w.Code, got, resp["pagination"].(map[string]interface{})
+	}
+
+	_, got, pagination := list("alice", "?limit=2")
+	assert.Equal(t, []string{ids[2], ids[1]}, got)
+	assert.Equal(t, true, pagination["has_next"])
+
+	_, got, pagination = list("alice", "?limit=2&cursor="+pagination["next_cursor"].(string))
+	assert.Equal(t, []string{ids[0]}, got)
+	assert.Equal(t, false, pagination["has_next"])
+
+	_, got, _ = list("bob", "?symbol=BTC/USDT")
+	assert.Equal(t, 3, len(got))
+	_, got, _ = list("bob", "?end_date=2000-01-01T00:00:00Z")
+	assert.Empty(t, got)
+	_, got, _ = list("carol", "")
+	assert.Empty(t, got)
+
+	for _, query := range []string{"?limit=0", "?limit=101", "?cursor=x", "?start_date=yesterday"} {
+		code, _, problem := list("alice", query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+		assert.Equal(t, problemTypeBase+"invalid-request", problem["type"], query)
+	}
+}
+
+func TestTradeAPI_RecentTrades(t *testing.T) {
+	router, _ := newTestAPI(t)
+
+	first := tradeOrders(t, router, "alice", "bob", "1", "50000")
+	second := tradeOrders(t, router, "bob", "alice", "0.5", "50100")
+
+	w, resp := request(t, router, http.MethodGet, "/api/v1/market-data/trades/BTC%2FUSDT", "", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	data := resp["data"].([]interface{})
+	require.Equal(t, 2, len(data))
+
+	// Newest first, without the users
+	trade := data[0].(map[string]interface{})
+	assert.Equal(t, second, trade["trade_id"])
+	assert.Equal(t, "BTC/USDT", trade["symbol"])
+	assert.Equal(t, "50100", trade["price"])
+	assert.Equal(t, "0.5", trade["quantity"])
+	assert.Equal(t, false, trade["is_buyer_maker"])
+	assert.NotContains(t, trade, "user_id")
+	assert.Equal(t, first, data[1].(map[string]interface{})["trade_id"])
+
+	_, resp = request(t, router, http.MethodGet, "/api/v1/market-data/trades/BTC%2FUSDT?limit=1", "", "")
+	assert.Equal(t, 1, len(resp["data"].([]interface{})))
+
+	w, _ = request(t, router, http.MethodGet, "/api/v1/market-data/trades/DOGE%2FUSDT", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w, _ = request(t, router, http.MethodGet, "/api/v1/market-data/trades/BTC%2FUSDT?limit=500", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
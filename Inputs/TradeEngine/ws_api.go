@@ -0,0 +1,201 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - WEBSOCKET API
+// ============================================================================
+// Project: MyTrader White-Label Kripto Exchange Platform
+// Component: Trade Engine Server (WebSocket)
+// Description: /ws endpoint: clients subscribe to the market data channels
+//              of market_stream.go over one connection
+//
+// Requests are JSON text messages:
+//
+//	{"type": "SUBSCRIBE", "channels": ["orderbook.BTC/USDT@10", "trades.BTC/USDT", "ticker.BTC/USDT"]}
+//	{"type": "UNSUBSCRIBE", "channels": ["trades.BTC/USDT"]}
+//	{"type": "RESYNC", "channels": ["orderbook.BTC/USDT@10"]}
+//
+// A subscription starts with the channel's state (ORDERBOOK_SNAPSHOT,
+// TICKER, or SUBSCRIBED for trades), followed by ORDERBOOK_UPDATE, TRADE and
+// TICKER updates. Each update's sequence is the previous message's + 1; on a
+// gap, send RESYNC and drop updates until the new state arrives. A
+// connection too far behind to take the state is closed instead; reconnect
+// and subscribe again.
+// ============================================================================
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// Client request types
+const (
+	requestSubscribe   = "SUBSCRIBE"
+	requestUnsubscribe = "UNSUBSCRIBE"
+	requestResync      = "RESYNC"
+)
+
+// Connection limits
+const (
+	wsSendBuffer     = 256 // Messages queued per client before it misses updates
+	wsWriteWait      = 10 * time.Second
+	wsPongWait       = 60 * time.Second
+	wsPingPeriod     = wsPongWait * 9 / 10
+	wsMaxMessageSize = 4096
+)
+
+// checkOrigin accepts clients without an Origin header (not browsers) and
+// browsers on one of the allowed origins (server.allowed_origins)
+func checkOrigin(allowed []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+
+		for _, o := range allowed {
+			if strings.EqualFold(strings.TrimSpace(o), origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+type streamRequest struct {
+	Type     string   `json:"type"`
+	Channels []string `json:"channels"`
+}
+
+// wsClient is a WebSocket connection subscribed to market data channels
+type wsClient struct {
+	conn *websocket.Conn
+	send chan []byte
+}
+
+// deliver queues a message, dropping it when the client is behind
+func (c *wsClient) deliver(msg []byte) {
+	select {
+	case c.send <- msg:
+	default:
+	}
+}
+
+// deliverState queues a channel state the client waits for. A client too
+// far behind to take it is disconnected instead of waiting forever; it
+// reconnects and subscribes again.
+func (c *wsClient) deliverState(msg []byte) {
+	select {
+	case c.send <- msg:
+	default:
+		c.conn.Close() // Ends readLoop, which cleans up
+	}
+}
+
+func (c *wsClient) replyError(channel string, err error) {
+	c.deliver(encodeMessage(streamMessage{Type: msgError, Channel: channel, Timestamp: time.Now(), Message: err.Error()}))
+}
+
+// registerStreamRoutes adds the /ws endpoint, open to browsers on
+// allowedOrigins
+func registerStreamRoutes(router *gin.Engine, stream *marketStream, allowedOrigins []string) {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     checkOrigin(allowedOrigins),
+	}
+
+	router.GET("/ws", func(c *gin.Context) {
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			return // Upgrade has replied with an HTTP error
+		}
+
+		client := &wsClient{conn: conn, send: make(chan []byte, wsSendBuffer)}
+		go client.writeLoop()
+		client.readLoop(stream)
+	})
+}
+
+// readLoop handles the client's requests until the connection fails
+func (c *wsClient) readLoop(stream *marketStream) {
+	defer func() {
+		stream.unsubscribeAll(c)
+		close(c.send)
+	}()
+
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req streamRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.replyError("", fmt.Errorf("invalid request: %v", err))
+			continue
+		}
+		c.handle(stream, req)
+	}
+}
+
+func (c *wsClient) handle(stream *marketStream, req streamRequest) {
+	switch req.Type {
+	case requestSubscribe, requestResync, requestUnsubscribe:
+	default:
+		c.replyError("", fmt.Errorf("unknown request type %q", req.Type))
+		return
+	}
+
+	for _, channel := range req.Channels {
+		var err error
+		if req.Type == requestUnsubscribe {
+			err = stream.unsubscribe(c, channel)
+		} else {
+			err = stream.subscribe(c, channel)
+		}
+		if err != nil {
+			c.replyError(channel, err)
+		}
+	}
+}
+
+// writeLoop writes queued messages and keeps the connection alive with pings
+func (c *wsClient) writeLoop() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
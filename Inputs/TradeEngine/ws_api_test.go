@@ -0,0 +1,167 @@
+// ============================================================================
+// MYTRADER TRADE ENGINE - WEBSOCKET API TESTS
+// ============================================================================
+
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/mytrader/trade-engine/internal/matching"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamURL serves the /ws endpoint of s to browsers on allowedOrigins
+func streamURL(t *testing.T, s *marketStream, allowedOrigins ...string) string {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registerStreamRoutes(router, s, allowedOrigins)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+func dialStream(t *testing.T, s *marketStream) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(streamURL(t, s), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn) testMessage {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var msg testMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func TestWebSocketAPI_AllowedOrigins(t *testing.T) {
+	engine, _ := newStreamEngine(t)
+	url := streamURL(t, newTestStream(t, engine), "https://app.mytrader.com")
+
+	dial := func(origin string) (int, error) {
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+		if err == nil {
+			conn.Close()
+		}
+		if resp == nil {
+			return 0, err
+		}
+		return resp.StatusCode, err
+	}
+
+	status, err := dial("https://app.mytrader.com")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, status)
+
+	// Clients that are not browsers send no Origin
+	_, err = dial("")
+	assert.NoError(t, err)
+
+	status, err = dial("https://evil.example.com")
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, status)
+}
+
+func TestWebSocketAPI(t *testing.T) {
+	engine, _ := newStreamEngine(t)
+	limitOrder(t, engine, "alice", matching.SideSell, "1", "50000")
+	s := newTestStream(t, engine)
+	conn := dialStream(t, s)
+
+	require.NoError(t, conn.WriteJSON(streamRequest{
+		Type:     requestSubscribe,
+		Channels: []string{"orderbook.BTC/USDT@5", "trades.BTC/USDT", "candles.BTC/USDT"},
+	}))
+
+	msg := readMessage(t, conn)
+	assert.Equal(t, msgOrderBookSnapshot, msg.Type)
+	assert.Equal(t, [][]string{{"50000", "1"}}, msg.depth(t).Asks)
+
+	msg = readMessage(t, conn)
+	assert.Equal(t, msgSubscribed, msg.Type)
+	assert.Equal(t, "trades.BTC/USDT", msg.Channel)
+
+	msg = readMessage(t, conn)
+	assert.Equal(t, msgError, msg.Type)
+	assert.Equal(t, "candles.BTC/USDT", msg.Channel)
+	assert.Contains(t, msg.Message, "unknown kind")
+
+	// A trade reaches the client as a trade, then as a depth update
+	limitOrder(t, engine, "bob", matching.SideBuy, "1", "50000")
+
+	msg = readMessage(t, conn)
+	assert.Equal(t, msgTrade, msg.Type)
+	assert.Equal(t, uint64(1), msg.Sequence)
+
+	msg = readMessage(t, conn)
+	assert.Equal(t, msgOrderBookUpdate, msg.Type)
+	assert.Equal(t, uint64(1), msg.Sequence)
+	assert.Equal(t, [][]string{{"50000", "0"}}, msg.depth(t).Asks)
+
+	// Requests the server cannot read
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("{")))
+	msg = readMessage(t, conn)
+	assert.Equal(t, msgError, msg.Type)
+
+	require.NoError(t, conn.WriteJSON(streamRequest{Type: "PING"}))
+	msg = readMessage(t, conn)
+	assert.Equal(t, msgError, msg.Type)
+	assert.Contains(t, msg.Message, "PING")
+
+	require.NoError(t, conn.WriteJSON(streamRequest{Type: requestUnsubscribe, Channels: []string{"trades.BTC/USDT"}}))
+	msg = readMessage(t, conn)
+	assert.Equal(t, msgUnsubscribed, msg.Type)
+
+	// Closing the connection removes its subscriptions
+	conn.Close()
+	assert.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.clients) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocketAPI_StateClosesLaggingClient(t *testing.T) {
+	conns := make(chan *websocket.Conn, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if conn, err := upgrader.Upgrade(w, r, nil); err == nil {
+			conns <- conn
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	peer, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { peer.Close() })
+
+	// Without a write loop the queue stays full: updates are dropped
+	client := &wsClient{conn: <-conns, send: make(chan []byte, 2)}
+	client.deliverState([]byte("state"))
+	client.deliver([]byte("update"))
+	client.deliver([]byte("update"))
+	assert.Equal(t, 2, len(client.send))
+
+	// A state that does not fit ends the connection rather than going missing
+	client.deliverState([]byte("state"))
+	require.NoError(t, peer.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err = peer.ReadMessage()
+	require.Error(t, err)
+	var netErr net.Error
+	assert.False(t, errors.As(err, &netErr) && netErr.Timeout(), err)
+}